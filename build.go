@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gilwo/wordscvc/cvc"
+)
+
+const buildHelp = `commands:
+  add WORD...       add the words to the current set
+  rm WORD...        remove the words from their set
+  set N             move to set N (up to one past the last set)
+  clear             remove all the words of the current set
+  show              show the group sets
+  words [PATTERN]   list all the words the current set can take, patterns
+                    like the query command (v=A c1=SH,TZ)
+  save FILE         write the sets as a seed file (generate -s FILE)
+  help              show this help
+  quit              leave
+`
+
+// builder is the interactive set builder state, the sets are kept as word
+// lists and replayed through the set rules on every change
+type builder struct {
+	wmap   *cvc.WordMap
+	tables *wordTables
+	byName map[string]*cvc.Word
+	sets   []cvc.WordList
+	cur    int
+}
+
+// build compose a group of sets by hand, explaining the rules each added
+// word breaks (the build command)
+func build(args []string) error {
+	wmap, tables, err := loadWords(&GenVarOpts.flagOpts)
+	if err != nil {
+		return err
+	}
+	b := &builder{wmap: wmap, tables: tables, byName: make(map[string]*cvc.Word), sets: []cvc.WordList{nil}}
+	for _, w := range wmap.Words() {
+		b.byName[w.String()] = w
+	}
+
+	fmt.Print(buildHelp)
+	b.status()
+	in := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Printf("set %d> ", b.cur+1)
+		if !in.Scan() {
+			fmt.Println()
+			return in.Err()
+		}
+		fields := strings.Fields(in.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		cmd := fields[0]
+		var words []string
+		for _, f := range fields[1:] {
+			words = append(words, strings.ToUpper(f))
+		}
+
+		switch cmd {
+		case "add":
+			for _, name := range words {
+				b.add(name)
+			}
+		case "rm":
+			for _, name := range words {
+				b.remove(name)
+			}
+		case "set":
+			n, err := strconv.Atoi(strings.Join(words, ""))
+			if err != nil || n < 1 || n > len(b.sets)+1 || n > GenVarOpts.MaxSets {
+				fmt.Printf("set should be 1 to %d\n", minInt(len(b.sets)+1, GenVarOpts.MaxSets))
+				continue
+			}
+			if n > len(b.sets) {
+				b.sets = append(b.sets, nil)
+			}
+			b.cur = n - 1
+		case "clear":
+			b.sets[b.cur] = nil
+		case "show":
+			b.show()
+			continue
+		case "words":
+			b.candidates(fields[1:], 0)
+			continue
+		case "save":
+			if len(words) != 1 {
+				fmt.Println("save needs a file name")
+			} else if err := b.save(fields[1]); err != nil {
+				fmt.Printf("failed to save: %v\n", err)
+			} else {
+				fmt.Printf("saved %d sets to %s\n", len(b.sets), fields[1])
+			}
+			continue
+		case "help":
+			fmt.Print(buildHelp)
+			continue
+		case "quit", "exit":
+			return nil
+		default:
+			fmt.Printf("unknown command '%s', try help\n", cmd)
+			continue
+		}
+		b.status()
+	}
+}
+
+// set replay the words of set i through the set rules
+func (b *builder) set(i int) *cvc.WordSet {
+	set := cvc.NewSetLimitFreq(GenVarOpts.MaxWords,
+		GenVarOpts.FreqCutoff, GenVarOpts.FreqWordsPerLineAboveCutoff)
+	for _, w := range b.sets[i] {
+		set.AddWord(w)
+	}
+	return set
+}
+
+// usedIn return the set (one based) holding the word, 0 when none does
+func (b *builder) usedIn(w *cvc.Word) int {
+	for i, set := range b.sets {
+		for _, e := range set {
+			if e == w {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// check return why the word can not be added to the current set, following
+// the group and set rules
+func (b *builder) check(w *cvc.Word) error {
+	if n := b.usedIn(w); n != 0 {
+		return fmt.Errorf("already used in set %d", n)
+	}
+	return b.set(b.cur).CheckWord(w)
+}
+
+func (b *builder) add(name string) {
+	w, ok := b.byName[name]
+	if !ok {
+		fmt.Printf("%s: not in the words list\n", name)
+		return
+	}
+	if err := b.check(w); err != nil {
+		fmt.Printf("%s: %v\n", b.text(w), err)
+		return
+	}
+	b.sets[b.cur] = append(b.sets[b.cur], w)
+
+	if len(b.sets[b.cur]) == GenVarOpts.MaxWords && b.cur == len(b.sets)-1 &&
+		len(b.sets) < GenVarOpts.MaxSets {
+		fmt.Printf("set %d is complete, moving to set %d\n", b.cur+1, b.cur+2)
+		b.sets = append(b.sets, nil)
+		b.cur++
+	}
+}
+
+func (b *builder) remove(name string) {
+	for i, set := range b.sets {
+		for j, w := range set {
+			if w.String() == name {
+				b.sets[i] = append(set[:j:j], set[j+1:]...)
+				return
+			}
+		}
+	}
+	fmt.Printf("%s: not in any set\n", name)
+}
+
+// text return the word for display, see placementText
+func (b *builder) text(w *cvc.Word) string {
+	return placementText(&GenVarOpts.flagOpts, b.tables, cvc.WordPlacement(w, GenVarOpts.FreqCutoff))
+}
+
+func (b *builder) show() {
+	for i, set := range b.sets {
+		var words []string
+		for _, w := range set {
+			p := cvc.WordPlacement(w, GenVarOpts.FreqCutoff)
+			words = append(words, fmt.Sprintf("%s:%d", placementText(&GenVarOpts.flagOpts, b.tables, p), p.Freq))
+		}
+		mark := " "
+		if i == b.cur {
+			mark = "*"
+		}
+		fmt.Printf("%s%d:[%s]\n", mark, i+1, strings.Join(words, ", "))
+	}
+}
+
+// status show the current set, its free consonants and vowels and the words
+// it can still take
+func (b *builder) status() {
+	usedC := make(map[string]bool)
+	usedV := make(map[string]int)
+	var above int
+	for _, w := range b.sets[b.cur] {
+		p := cvc.WordPlacement(w, GenVarOpts.FreqCutoff)
+		usedC[p.Onset], usedC[p.Coda] = true, true
+		usedV[p.Vowel]++
+		if p.Above {
+			above++
+		}
+	}
+
+	var freeC, freeV []string
+	for c := range b.tables.consonants {
+		if !usedC[c] {
+			freeC = append(freeC, b.tables.showPhoneme(c))
+		}
+	}
+	for v := range b.tables.vowels {
+		if usedV[v] < 2 {
+			freeV = append(freeV, fmt.Sprintf("%s(%d)", b.tables.showPhoneme(v), 2-usedV[v]))
+		}
+	}
+	sort.Strings(freeC)
+	sort.Strings(freeV)
+
+	fmt.Printf("set %d: %d of %d words", b.cur+1, len(b.sets[b.cur]), GenVarOpts.MaxWords)
+	if GenVarOpts.FreqCutoff != 0 {
+		fmt.Printf(", %d of %d above the frequency cutoff %d",
+			above, GenVarOpts.FreqWordsPerLineAboveCutoff, GenVarOpts.FreqCutoff)
+	}
+	fmt.Printf("\nfree consonants: %s\nfree vowels: %s\n",
+		strings.Join(freeC, " "), strings.Join(freeV, " "))
+	b.candidates(nil, 20)
+}
+
+// candidates list the words the current set can take which match all the
+// patterns, at most limit of them (0 for all)
+func (b *builder) candidates(patterns []string, limit int) {
+	var rules []*filterRule
+	for _, pattern := range patterns {
+		r, err := newFilterRule(0, "include "+pattern)
+		if err != nil {
+			fmt.Printf("pattern '%s': %v\n", pattern, err)
+			return
+		}
+		rules = append(rules, r)
+	}
+
+	var found []*cvc.Word
+	for _, w := range b.wmap.Words() {
+		p := cvc.WordPlacement(w, 0)
+		match := true
+		for _, r := range rules {
+			match = match && r.match(p.Onset, p.Vowel, p.Coda)
+		}
+		if match && b.check(w) == nil {
+			found = append(found, w)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].String() < found[j].String() })
+
+	var words []string
+	for i, w := range found {
+		if limit > 0 && i == limit {
+			words = append(words, "...")
+			break
+		}
+		words = append(words, b.text(w))
+	}
+	fmt.Printf("%d words can be added: %s\n", len(found), strings.Join(words, " "))
+}
+
+// save write the sets in the seed file format
+func (b *builder) save(fname string) error {
+	out := ""
+	for i, set := range b.sets {
+		if len(set) == 0 {
+			continue
+		}
+		var words []string
+		for _, w := range set {
+			words = append(words, w.String())
+		}
+		out += fmt.Sprintf("%d:[%s]\n", i+1, strings.Join(words, ", "))
+	}
+	return ioutil.WriteFile(fname, []byte(out), 0644)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
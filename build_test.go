@@ -0,0 +1,67 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runBuild run the build command with input as the standard input and
+// return what it print
+func runBuild(t *testing.T, input string) string {
+	t.Helper()
+	in := writeTestFile(t, "input.txt", input)
+	f, err := os.Open(in)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", in, err)
+	}
+	defer f.Close()
+	saved := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = saved }()
+
+	out, err := captureOutput(t, func() error { return build(nil) })
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	return out
+}
+
+func TestBuild(t *testing.T) {
+	dir := setTestWords(t, testWordsList)
+	seed := filepath.Join(dir, "seed.txt")
+
+	out := runBuild(t, "add nop mil bad\nadd tur rut\nadd zap\nshow\nset 3\nsave "+seed+"\nquit\n")
+	for _, part := range []string{
+		"set 1: 0 of 2 words, 0 of 1 above the frequency cutoff 25\n",
+		"MIL: more than 1 words above the frequency cutoff 25\n",
+		"set 1 is complete, moving to set 2\n",
+		"RUT: consonant T is already used in the set\n",
+		"1 words can be added: MIL\n",
+		"ZAP: not in the words list\n",
+		"*2:[TUR:8]\n",
+		"set should be 1 to 2\n",
+		"saved 2 sets to " + seed + "\n",
+	} {
+		if !strings.Contains(out, part) {
+			t.Errorf("build output is missing '%s': '%s'", part, out)
+		}
+	}
+	data, err := ioutil.ReadFile(seed)
+	if err != nil {
+		t.Fatalf("failed to read the saved seed: %v", err)
+	}
+	if string(data) != "1:[NOP, BAD]\n2:[TUR]\n" {
+		t.Errorf("saved seed '%s'", data)
+	}
+
+	out = runBuild(t, "add bad\nrm bad\nrm bad\nwords v=U\nfoo\n")
+	for _, part := range []string{"BAD: not in any set\n", "2 words can be added: RUT TUR\n",
+		"unknown command 'foo', try help\n"} {
+		if !strings.Contains(out, part) {
+			t.Errorf("build output is missing '%s': '%s'", part, out)
+		}
+	}
+}
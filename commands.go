@@ -0,0 +1,199 @@
+package main
+
+import (
+	"github.com/jessevdk/go-flags"
+)
+
+// the commands options, each command take only the option groups it reads
+// (see sharedOpts) and the global options. go-flags set the defaults of
+// the options of all the commands, so every command parse the groups into
+// its own copy and apply copies them to GenVarOpts when the command runs
+
+// sharedCommand is implemented by the commands options taking shared options
+type sharedCommand interface {
+	apply(opts *flagOpts)
+}
+
+// generateCommand is the generate command options, its own options are
+// parsed right into GenVarOpts
+type generateCommand struct {
+	Opts     *generateOpts
+	Rules    ruleOpts     `group:"Rule Options"`
+	Alphabet alphabetOpts `group:"Alphabet Options"`
+	Lexicon  lexiconOpts  `group:"Words List Options"`
+	Display  displayOpts  `group:"Display Options"`
+}
+
+func (o *generateCommand) apply(opts *flagOpts) {
+	opts.ruleOpts, opts.alphabetOpts, opts.lexiconOpts, opts.displayOpts =
+		o.Rules, o.Alphabet, o.Lexicon, o.Display
+}
+
+type validateOpts struct {
+	Rules    ruleOpts     `group:"Rule Options"`
+	Alphabet alphabetOpts `group:"Alphabet Options"`
+	Lexicon  lexiconOpts  `group:"Words List Options"`
+	Display  displayOpts  `group:"Display Options"`
+
+	Format    string `long:"format" description:"format of the groups file, guessed from its extension when auto" choice:"auto" choice:"text" choice:"json" choice:"csv" choice:"tsv" choice:"html" default:"auto"`
+	Delimiter string `long:"delimiter" description:"field delimiter of a csv groups file ('tab' for a tab)" default:","`
+	Args      struct {
+		GroupsFile string `positional-arg-name:"groups-file" description:"groups written by generate in any output format" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+func (o *validateOpts) apply(opts *flagOpts) {
+	opts.ruleOpts, opts.alphabetOpts, opts.lexiconOpts, opts.displayOpts =
+		o.Rules, o.Alphabet, o.Lexicon, o.Display
+}
+
+type statsOpts struct {
+	Rules    ruleOpts     `group:"Rule Options"`
+	Alphabet alphabetOpts `group:"Alphabet Options"`
+	Lexicon  lexiconOpts  `group:"Words List Options"`
+	Display  displayOpts  `group:"Display Options"`
+}
+
+func (o *statsOpts) apply(opts *flagOpts) {
+	opts.ruleOpts, opts.alphabetOpts, opts.lexiconOpts, opts.displayOpts =
+		o.Rules, o.Alphabet, o.Lexicon, o.Display
+}
+
+type lintOpts struct {
+	Alphabet    alphabetOpts `group:"Alphabet Options"`
+	Display     displayOpts  `group:"Display Options"`
+	InWordsFile string       `short:"i" description:"input file name for words list to check (text, .json, .csv or .tsv lexicon)" optional:"1" default:"words_list.txt"`
+	Outlier     float64      `long:"outlier" description:"flag frequencies this many standard deviations from the mean (on a log scale), 0 to disable" default:"3"`
+}
+
+func (o *lintOpts) apply(opts *flagOpts) {
+	opts.alphabetOpts, opts.displayOpts = o.Alphabet, o.Display
+	opts.InWordsFile = o.InWordsFile
+}
+
+type queryOpts struct {
+	Alphabet   alphabetOpts `group:"Alphabet Options"`
+	Lexicon    lexiconOpts  `group:"Words List Options"`
+	Display    displayOpts  `group:"Display Options"`
+	FreqCutoff int          `short:"f" description:"frequency cutoff threshold the words are listed above or below" default:"25"`
+	Sort       string       `long:"sort" description:"order of the listed words" choice:"word" choice:"freq" default:"word"`
+	Limit      int          `short:"n" description:"list at most this many words (0 for all)"`
+	Args       struct {
+		Patterns []string `positional-arg-name:"pattern" description:"word or c1/v/c2 pattern (c2=SH,TZ), the listed words match all of them"`
+	} `positional-args:"yes"`
+}
+
+func (o *queryOpts) apply(opts *flagOpts) {
+	opts.alphabetOpts, opts.lexiconOpts, opts.displayOpts = o.Alphabet, o.Lexicon, o.Display
+	opts.FreqCutoff = o.FreqCutoff
+}
+
+type corpusOpts struct {
+	Alphabet     alphabetOpts `group:"Alphabet Options"`
+	InWordsFile  string       `short:"i" description:"input file name for words list to count (text, .json, .csv or .tsv lexicon)" optional:"1" default:"words_list.txt"`
+	TranslitFile string       `long:"translit" description:"input file name for the transliteration table used to match the words in hebrew script"`
+	Output       string       `short:"o" description:"output file for the updated words list (text, .json, .csv or .tsv lexicon)" required:"yes"`
+	Prefixes     []string     `long:"prefix" description:"prefix of the inflected variants counted with the word, in latin symbols or hebrew letters (repeat for more)"`
+	Suffixes     []string     `long:"suffix" description:"suffix of the inflected variants counted with the word, in latin symbols or hebrew letters (repeat for more)"`
+	Args         struct {
+		Corpus []string `positional-arg-name:"corpus" description:"plain text corpus files, transliterated or in hebrew script (with --translit)" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func (o *corpusOpts) apply(opts *flagOpts) {
+	opts.alphabetOpts = o.Alphabet
+	opts.InWordsFile, opts.TranslitFile = o.InWordsFile, o.TranslitFile
+}
+
+type buildOpts struct {
+	Rules    ruleOpts     `group:"Rule Options"`
+	Alphabet alphabetOpts `group:"Alphabet Options"`
+	Lexicon  lexiconOpts  `group:"Words List Options"`
+	Display  displayOpts  `group:"Display Options"`
+}
+
+func (o *buildOpts) apply(opts *flagOpts) {
+	opts.ruleOpts, opts.alphabetOpts, opts.lexiconOpts, opts.displayOpts =
+		o.Rules, o.Alphabet, o.Lexicon, o.Display
+}
+
+// serveOpts take the shared options as the defaults of the jobs
+type serveOpts struct {
+	Rules    ruleOpts     `group:"Rule Options"`
+	Alphabet alphabetOpts `group:"Alphabet Options"`
+	Lexicon  lexiconOpts  `group:"Words List Options"`
+	Display  displayOpts  `group:"Display Options"`
+
+	Addr     string `long:"addr" description:"address the http api listens on" default:"localhost:8080"`
+	Jobs     int    `long:"jobs" description:"number of jobs running at the same time, the other jobs wait queued" default:"2"`
+	DataDir  string `long:"data-dir" description:"directory the job files (alphabet, words, filter, transliteration and seed) are looked up in" default:"."`
+	MaxTime  int    `long:"max-time" description:"longest time to run (in seconds) a job can ask for" default:"600"`
+	KeepJobs int    `long:"keep-jobs" description:"number of finished jobs kept, the oldest finished jobs are dropped as new jobs are submitted" default:"100"`
+}
+
+func (o *serveOpts) apply(opts *flagOpts) {
+	opts.ruleOpts, opts.alphabetOpts, opts.lexiconOpts, opts.displayOpts =
+		o.Rules, o.Alphabet, o.Lexicon, o.Display
+}
+
+var GenGenerateOpts = generateCommand{Opts: &GenVarOpts.generateOpts}
+var GenValidateOpts validateOpts
+var GenStatsOpts statsOpts
+var GenLintOpts lintOpts
+var GenQueryOpts queryOpts
+var GenCorpusOpts corpusOpts
+var GenBuildOpts buildOpts
+var GenServeOpts serveOpts
+
+// addCommands register the commands and their options with the parser
+func addCommands(parser *flags.Parser) error {
+	for _, c := range []struct {
+		name, short, long string
+		data              interface{}
+	}{
+		{"generate", "generate groups of sets (default)",
+			"search for groups of word sets obeying the set and group rules", &GenGenerateOpts},
+		{"validate", "validate groups of sets",
+			"check that groups of sets obey the set and group rules", &GenValidateOpts},
+		{"stats", "lexicon statistics",
+			"report the words list statistics and the feasibility of the rules", &GenStatsOpts},
+		{"lint", "check the words list",
+			"report problems in the words list", &GenLintOpts},
+		{"query", "query the words list",
+			"list the words matching the given words and c1/v/c2 patterns", &GenQueryOpts},
+		{"corpus", "count the words in a corpus",
+			"write the words list with the words frequency counted in text corpus files", &GenCorpusOpts},
+		{"build", "build sets interactively",
+			"compose sets by hand, showing the free phonemes and the words each set can still take", &GenBuildOpts},
+		{"serve", "serve the http api",
+			"run generation jobs through an http api", &GenServeOpts},
+	} {
+		if _, err := parser.AddCommand(c.name, c.short, c.long, c.data); err != nil {
+			return err
+		}
+		if shared, ok := c.data.(sharedCommand); ok {
+			commandShared[c.name] = shared
+		}
+	}
+	return nil
+}
+
+// resetCommands clear the commands options, go-flags keep the values of the
+// options without a default (and append to the positional arguments) from
+// one parse to the next
+func resetCommands() {
+	GenGenerateOpts = generateCommand{Opts: &GenVarOpts.generateOpts}
+	GenValidateOpts, GenStatsOpts, GenLintOpts = validateOpts{}, statsOpts{}, lintOpts{}
+	GenQueryOpts, GenCorpusOpts, GenBuildOpts = queryOpts{}, corpusOpts{}, buildOpts{}
+	GenServeOpts = serveOpts{}
+}
+
+// commandShared are the commands taking shared options, by command name
+var commandShared = make(map[string]sharedCommand)
+
+// applyCommand copy the shared options of the command to GenVarOpts
+func applyCommand(name string) {
+	if shared, ok := commandShared[name]; ok {
+		shared.apply(&GenVarOpts.flagOpts)
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+)
+
+// configuration file, a json object keyed by the flagOpts json names, for
+// example:
+//
+//	{"max_sets": 20, "words": "words_list.txt", "format": "json", "verbose": [true]}
+//
+// the options precedence is: command line, configuration file, language
+// profile and then the built in defaults. a bool option set to true in the
+// configuration file (or profile) is turned off on the command line with
+// its false form, --ipa=false
+
+// configOpts are the options which are not part of the generation settings
+type configOpts struct {
+	ConfigFile string `long:"config" description:"4  input configuration file (json) for the options, the command line options override it (--name=false turn off its bool options)"`
+	DumpConfig bool   `long:"dump-config" description:"5  write the resolved configuration (json) and exit"`
+}
+
+// loadConfig read the configuration file and return its values by option
+// name
+func loadConfig(fname string) (map[string][]string, error) {
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %v", fname, err)
+	}
+
+	fields := make(map[string]reflect.StructField)
+	optionFields(reflect.TypeOf(flagOpts{}), fields)
+
+	values := make(map[string][]string)
+	for key, v := range raw {
+		f, ok := fields[key]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown option '%s'", fname, key)
+		}
+		if v == nil {
+			continue
+		}
+		items := []interface{}{v}
+		if list, ok := v.([]interface{}); ok {
+			items = list
+		}
+		name := optionName(f)
+		for _, item := range items {
+			value, err := configValue(f.Type, item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: option '%s': %v", fname, key, err)
+			}
+			values[name] = append(values[name], value)
+		}
+	}
+	return values, nil
+}
+
+// optionFields map the json names of the options struct t (and its
+// embedded structs) to the options fields
+func optionFields(t reflect.Type, fields map[string]reflect.StructField) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			optionFields(f.Type, fields)
+			continue
+		}
+		fields[strings.Split(f.Tag.Get("json"), ",")[0]] = f
+	}
+}
+
+// optionName return the option name of the field, its long name or else its
+// short one
+func optionName(f reflect.StructField) string {
+	if name := f.Tag.Get("long"); name != "" {
+		return name
+	}
+	return f.Tag.Get("short")
+}
+
+// configValue return the configuration value v of an option of type t (or
+// of a list of them) as a command line value, the json numbers of the
+// integer options must be whole
+func configValue(t reflect.Type, v interface{}) (string, error) {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	n, ok := v.(float64)
+	if !ok {
+		return fmt.Sprint(v), nil
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n != math.Trunc(n) {
+			return "", fmt.Errorf("%v is not a whole number", n)
+		}
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64), nil
+}
+
+// defaultCommand prepend the generate command to args when no command is
+// named, so the generate options work without it (unless asking for help)
+func defaultCommand(parser *flags.Parser, args []string) []string {
+	for _, arg := range args {
+		if arg == "-h" || arg == "--help" || parser.Find(arg) != nil {
+			return args
+		}
+	}
+	return append([]string{"generate"}, args...)
+}
+
+// boolArgs rewrite the --name=true and --name=false forms of the bool
+// options, which go-flags does not take: the true form become --name and
+// the false form is dropped, its option is returned (with no value) to
+// clear the default the configuration file or profile gave it
+func boolArgs(parser *flags.Parser, args []string) ([]string, map[string][]string) {
+	var out []string
+	off := make(map[string][]string)
+	for i, arg := range args {
+		if arg == "--" {
+			return append(out, args[i:]...), off
+		}
+		eq := strings.Index(arg, "=")
+		if !strings.HasPrefix(arg, "--") || eq == -1 || !isBoolOption(parser, arg[2:eq]) {
+			out = append(out, arg)
+			continue
+		}
+		on, err := strconv.ParseBool(arg[eq+1:])
+		switch {
+		case err != nil:
+			// left for go-flags to reject
+			out = append(out, arg)
+		case on:
+			out = append(out, arg[:eq])
+		default:
+			off[arg[2:eq]] = nil
+		}
+	}
+	return out, off
+}
+
+// isBoolOption return true when name is the long name of a bool option of
+// the parser or its commands
+func isBoolOption(parser *flags.Parser, name string) bool {
+	for _, c := range append([]*flags.Command{parser.Command}, parser.Commands()...) {
+		if opt := c.FindOptionByLongName(name); opt != nil {
+			t := reflect.TypeOf(opt.Value())
+			if t.Kind() == reflect.Slice {
+				t = t.Elem()
+			}
+			return t.Kind() == reflect.Bool
+		}
+	}
+	return false
+}
+
+// parseOpts parse the command line over the configuration file and language
+// profile values and return the command name and the remaining arguments
+func parseOpts() (string, []string, error) {
+	parser := flags.NewParser(&GenVarOpts.globalOpts, flags.Default)
+	parser.SubcommandsOptional = true
+	if _, err := parser.AddGroup("Configuration Options", "", &GenConfigOpts); err != nil {
+		return "", nil, err
+	}
+	if err := addCommands(parser); err != nil {
+		return "", nil, err
+	}
+
+	args, off := boolArgs(parser, defaultCommand(parser, os.Args[1:]))
+	a, err := parser.ParseArgs(args)
+	checkOptsErr(err)
+
+	var config map[string][]string
+	if GenConfigOpts.ConfigFile != "" {
+		if config, err = loadConfig(GenConfigOpts.ConfigFile); err != nil {
+			return "", nil, fmt.Errorf("failed to load config: %v", err)
+		}
+	}
+
+	profile := GenVarOpts.Profile
+	if profile == "" && len(config["profile"]) > 0 {
+		profile = config["profile"][0]
+	}
+	if profile != "" {
+		prof, err := loadProfile(profile)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to load profile: %v", err)
+		}
+		if err = setDefaults(parser, prof.defaults()); err != nil {
+			return "", nil, fmt.Errorf("profile '%s': %v", profile, err)
+		}
+		info("using profile '%s' from '%s'\n", prof.Name, profile)
+	}
+	if err = setDefaults(parser, config); err != nil {
+		return "", nil, fmt.Errorf("config '%s': %v", GenConfigOpts.ConfigFile, err)
+	}
+	if err = setDefaults(parser, off); err != nil {
+		return "", nil, err
+	}
+
+	// the profile and configuration values are the options defaults now,
+	// parse again so the command line options override them
+	if profile != "" || config != nil || len(off) > 0 {
+		GenVarOpts = varOpts{}
+		resetCommands()
+		a, err = parser.ParseArgs(args)
+		checkOptsErr(err)
+	}
+
+	if parser.Active != nil {
+		applyCommand(parser.Active.Name)
+	}
+
+	if GenConfigOpts.DumpConfig {
+		data, err := json.MarshalIndent(GenVarOpts.flagOpts, "", "  ")
+		if err != nil {
+			return "", nil, err
+		}
+		fmt.Println(string(data))
+		os.Exit(0)
+	}
+
+	return parser.Active.Name, a, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigLoad(t *testing.T) {
+	values, err := loadConfig(writeTestFile(t, "config.json",
+		`{"max_sets": 20, "words": "w.txt", "verbose": [true, true], "freq_cutoff": null}`))
+	if err != nil {
+		t.Fatalf("failed to load the config: %v", err)
+	}
+	expected := "map[S:[20] i:[w.txt] v:[true true]]"
+	if actual := fmt.Sprint(values); actual != expected {
+		t.Errorf("config values: expected '%s', actual '%s'", expected, actual)
+	}
+
+	if _, err = loadConfig(writeTestFile(t, "config.json", `{"max_words": 2.5}`)); err == nil ||
+		!strings.Contains(err.Error(), "option 'max_words': 2.5 is not a whole number") {
+		t.Errorf("config with a fraction for a number: %v", err)
+	}
+	if _, err = loadConfig(writeTestFile(t, "config.json", `{"colour": "red"}`)); err == nil ||
+		!strings.Contains(err.Error(), "unknown option 'colour'") {
+		t.Errorf("config with an unknown option: %v", err)
+	}
+	if _, err = loadConfig(writeTestFile(t, "config.json", `max_sets = 20`)); err == nil {
+		t.Errorf("config which is not json loaded")
+	}
+}
+
+// resetOpts clear the options of all the commands, go-flags keep the values
+// of the options without a default from one parse to the next
+func resetOpts() {
+	GenVarOpts, GenConfigOpts = varOpts{}, configOpts{}
+	GenGenerateOpts = generateCommand{Opts: &GenVarOpts.generateOpts}
+	GenValidateOpts, GenStatsOpts, GenLintOpts = validateOpts{}, statsOpts{}, lintOpts{}
+	GenQueryOpts, GenCorpusOpts, GenBuildOpts = queryOpts{}, corpusOpts{}, buildOpts{}
+	GenServeOpts = serveOpts{}
+}
+
+// parseTestArgs parse args as the command line and return the command and
+// the options it resolved to, as "-S -W -f -C --ipa"
+func parseTestArgs(t *testing.T, args ...string) (string, string) {
+	t.Helper()
+	saved := os.Args
+	defer func() { os.Args = saved }()
+
+	resetOpts()
+	os.Args = append([]string{"wordscvc"}, args...)
+	cmd, _, err := parseOpts()
+	if err != nil {
+		t.Fatalf("failed to parse %v: %v", args, err)
+	}
+	o := GenVarOpts
+	return cmd, fmt.Sprintf("%d %d %d %s %v", o.MaxSets, o.MaxWords, o.FreqCutoff, o.InConsonantFile, o.IPA)
+}
+
+// the options precedence: command line, configuration file, language
+// profile and then the built in defaults
+func TestConfigPrecedence(t *testing.T) {
+	defer resetOpts()
+	profile := writeTestFile(t, "profile.json",
+		`{"name": "test", "consonants": "c.txt", "max_sets": 12, "max_words": 8, "freq_cutoff": 30}`)
+	consonants := filepath.Join(filepath.Dir(profile), "c.txt")
+	config := writeTestFile(t, "config.json",
+		`{"profile": "`+profile+`", "max_sets": 20, "ipa": true}`)
+
+	if _, opts := parseTestArgs(t); opts != "15 10 25 consonants.txt false" {
+		t.Errorf("default options '%s'", opts)
+	}
+	if _, opts := parseTestArgs(t, "--profile", profile); opts != "12 8 30 "+consonants+" false" {
+		t.Errorf("profile options '%s'", opts)
+	}
+	if _, opts := parseTestArgs(t, "--config", config); opts != "20 8 30 "+consonants+" true" {
+		t.Errorf("config options '%s'", opts)
+	}
+	if _, opts := parseTestArgs(t, "--config", config, "-S", "4", "-Cx.txt"); opts != "4 8 30 x.txt true" {
+		t.Errorf("command line options '%s'", opts)
+	}
+
+	// the false form of a bool option turn off the configuration value
+	if _, opts := parseTestArgs(t, "--config", config, "--ipa=false"); opts != "20 8 30 "+consonants+" false" {
+		t.Errorf("command line bool option off '%s'", opts)
+	}
+	if _, opts := parseTestArgs(t, "stats", "--ipa=true"); opts != "15 10 25 consonants.txt true" {
+		t.Errorf("command line bool option on '%s'", opts)
+	}
+}
+
+func TestConfigCommands(t *testing.T) {
+	defer resetOpts()
+	profile := writeTestFile(t, "profile.json",
+		`{"name": "test", "consonants": "c.txt", "max_sets": 12, "max_words": 8, "freq_cutoff": 30}`)
+	consonants := filepath.Join(filepath.Dir(profile), "c.txt")
+	config := writeTestFile(t, "config.json", `{"profile": "`+profile+`", "max_sets": 20}`)
+
+	// generate is the default command
+	cmd, opts := parseTestArgs(t, "--config", config, "-W", "3")
+	if cmd != "generate" || opts != "20 3 30 "+consonants+" false" {
+		t.Errorf("no command parsed as %s '%s'", cmd, opts)
+	}
+	cmd, opts = parseTestArgs(t, "--config", config, "generate", "-S", "4")
+	if cmd != "generate" || opts != "4 8 30 "+consonants+" false" {
+		t.Errorf("generate parsed as %s '%s'", cmd, opts)
+	}
+	cmd, opts = parseTestArgs(t, "--profile", profile, "stats", "-f", "40")
+	if cmd != "stats" || opts != "12 8 40 "+consonants+" false" {
+		t.Errorf("stats parsed as %s '%s'", cmd, opts)
+	}
+	// query take no rule options but the frequency cutoff
+	cmd, opts = parseTestArgs(t, "--profile", profile, "query", "v=A")
+	if cmd != "query" || opts != "0 0 30 "+consonants+" false" || fmt.Sprint(GenQueryOpts.Args.Patterns) != "[v=A]" {
+		t.Errorf("query parsed as %s '%s' %v", cmd, opts, GenQueryOpts.Args.Patterns)
+	}
+}
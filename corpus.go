@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// hebrewFinals map the final letters to their form inside a word, used when
+// a suffix follows the word
+var hebrewFinals = strings.NewReplacer("ך", "כ", "ם", "מ", "ן", "נ", "ף", "פ", "ץ", "צ")
+
+// corpus count the words list words (and their inflected variants) in the
+// corpus files and write the words list with the counts as frequencies (the
+// corpus command)
+func corpus(args []string) error {
+	tables, err := loadAlphabet(&GenVarOpts.flagOpts)
+	if err != nil {
+		return err
+	}
+	entries, err := loadLexicon(GenVarOpts.InWordsFile)
+	if err != nil {
+		return err
+	}
+
+	forms, err := corpusForms(tables, entries)
+	if err != nil {
+		return err
+	}
+
+	counts := make([]int, len(entries))
+	var tokens, matched, hebrew int
+	for _, fname := range append(GenCorpusOpts.Args.Corpus, args...) {
+		err := corpusTokens(fname, func(tok string) {
+			tokens++
+			if isHebrew(tok) {
+				hebrew++
+			} else {
+				tok = strings.ToUpper(tok)
+			}
+			if words, ok := forms[tok]; ok {
+				matched++
+				for _, i := range words {
+					counts[i]++
+				}
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if hebrew > 0 && tables.translit == nil {
+		fmt.Printf("warning: %d hebrew script words were not matched, no transliteration table given (--translit)\n", hebrew)
+	}
+
+	var missing int
+	for i := range entries {
+		entries[i].freq = counts[i]
+		if counts[i] == 0 {
+			missing++
+		}
+	}
+	if err := writeLexicon(GenCorpusOpts.Output, entries); err != nil {
+		return err
+	}
+	fmt.Printf("%d corpus words, %d matched, %d of %d words list words not found, written to %s\n",
+		tokens, matched, missing, len(entries), GenCorpusOpts.Output)
+	return nil
+}
+
+// corpusForms return the entries indexes by the forms counted for them: the
+// word and its prefixed and suffixed variants, in latin symbols and (with a
+// transliteration table or a hebrew spelling) in hebrew script
+func corpusForms(tables *wordTables, entries []lexEntry) (map[string][]int, error) {
+	forms := make(map[string][]int)
+	add := func(form string, i int) {
+		words := forms[form]
+		if len(words) == 0 || words[len(words)-1] != i {
+			forms[form] = append(words, i)
+		}
+	}
+	variants := func(word string, hebrewScript bool, i int) {
+		medial := word
+		if hebrewScript {
+			medial = hebrewFinals.Replace(word)
+		}
+		add(word, i)
+		for _, p := range GenCorpusOpts.Prefixes {
+			if isHebrew(p) == hebrewScript {
+				add(strings.ToUpper(p)+word, i)
+			}
+		}
+		for _, s := range GenCorpusOpts.Suffixes {
+			if isHebrew(s) != hebrewScript {
+				continue
+			}
+			add(medial+strings.ToUpper(s), i)
+			for _, p := range GenCorpusOpts.Prefixes {
+				if isHebrew(p) == hebrewScript {
+					add(strings.ToUpper(p)+medial+strings.ToUpper(s), i)
+				}
+			}
+		}
+	}
+
+	for i, e := range entries {
+		variants(e.word, false, i)
+
+		spelling, ok := e.meta["hebrew"]
+		if !ok && tables.translit != nil {
+			c1, v, c2, err := e.split(tables.vowels)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %v", GenVarOpts.InWordsFile, e.line, err)
+			}
+			if spelling, err = tables.render(c1, v, c2, false); err != nil {
+				return nil, fmt.Errorf("word '%s': %v", e.word, err)
+			}
+		}
+		if spelling = stripMarks(spelling); spelling != "" {
+			variants(spelling, true, i)
+		}
+	}
+	return forms, nil
+}
+
+// corpusTokens call found with each word of the corpus file, the niqqud and
+// other marks are dropped and anything which is not a letter separate words
+func corpusTokens(fname string, found func(string)) error {
+	f, err := os.Open(fname)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadString('\n')
+		for _, tok := range strings.FieldsFunc(stripMarks(line), func(r rune) bool {
+			return !unicode.IsLetter(r)
+		}) {
+			found(tok)
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%s: %v", fname, err)
+		}
+	}
+}
+
+// stripMarks drop the non spacing marks (niqqud) of s
+func stripMarks(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCorpusForms(t *testing.T) {
+	translit, err := loadTranslit(writeTestFile(t, "hebrew.txt", "c SH ש שׁ\nc M  מ/ם\nc R  ר\nv O  ו\nv A  -\n"))
+	if err != nil {
+		t.Fatalf("failed to load the transliteration table: %v", err)
+	}
+	entries := []lexEntry{
+		{line: 1, word: "SHOM"},
+		// the hebrew spelling given with niqqud is counted without it
+		{line: 2, word: "MAR", meta: map[string]string{"hebrew": "מַר"}},
+	}
+	resetOpts()
+	defer resetOpts()
+	GenCorpusOpts.Prefixes = []string{"ha", "ה"}
+	GenCorpusOpts.Suffixes = []string{"im", "ים"}
+
+	forms, err := corpusForms(&wordTables{vowels: map[string]int{"O": 1, "A": 1}, translit: translit}, entries)
+	if err != nil {
+		t.Fatalf("failed to build the forms: %v", err)
+	}
+	for form, expected := range map[string]string{
+		"SHOM": "[0]", "HASHOM": "[0]", "SHOMIM": "[0]", "HASHOMIM": "[0]",
+		"שום": "[0]", "השום": "[0]", "שומים": "[0]", "השומים": "[0]",
+		"MAR": "[1]", "HAMARIM": "[1]", "מר": "[1]", "המרים": "[1]",
+		"SHOMHA": "[]", "שוםים": "[]",
+	} {
+		if actual := fmt.Sprint(forms[form]); actual != expected {
+			t.Errorf("form %s counted for %s, expected %s", form, actual, expected)
+		}
+	}
+
+	// with no transliteration table only the given hebrew spelling is known
+	if forms, err = corpusForms(&wordTables{vowels: map[string]int{"O": 1, "A": 1}}, entries); err != nil {
+		t.Fatalf("failed to build the forms with no transliteration: %v", err)
+	}
+	if fmt.Sprint(forms["HASHOMIM"], forms["השום"], forms["המרים"]) != "[0] [] [1]" {
+		t.Errorf("forms with no transliteration %v", forms)
+	}
+}
+
+func TestCorpusTokens(t *testing.T) {
+	var tokens []string
+	found := func(tok string) { tokens = append(tokens, tok) }
+
+	if err := corpusTokens(writeTestFile(t, "corpus.txt", "Ha-shom, the shom!\nmar"), found); err != nil {
+		t.Fatalf("failed to read the corpus: %v", err)
+	}
+	if strings.Join(tokens, " ") != "Ha shom the shom mar" {
+		t.Errorf("corpus tokens '%s'", strings.Join(tokens, " "))
+	}
+
+	tokens = nil
+	if err := corpusTokens(writeTestFile(t, "corpus.txt", "שָׁלוֹם, השום 12 x"), found); err != nil {
+		t.Fatalf("failed to read the hebrew corpus: %v", err)
+	}
+	if strings.Join(tokens, " ") != "שלום השום x" {
+		t.Errorf("hebrew corpus tokens '%s'", strings.Join(tokens, " "))
+	}
+}
+
+func TestCorpus(t *testing.T) {
+	dir := setTestWords(t, "BAD: 1\nGEK: 1\nNOP: 1\n")
+	GenCorpusOpts.Prefixes = []string{"ha"}
+	GenCorpusOpts.Output = filepath.Join(dir, "counted.txt")
+	corpusFile := writeTestFile(t, "corpus.txt", "bad gek, habad\nBAD nop-nop-nop")
+
+	out, err := captureOutput(t, func() error { return corpus([]string{corpusFile}) })
+	if err != nil {
+		t.Fatalf("corpus failed: %v", err)
+	}
+	if !strings.HasPrefix(out, "7 corpus words, 7 matched, 0 of 3 words list words not found") {
+		t.Errorf("corpus report '%s'", out)
+	}
+	data, err := ioutil.ReadFile(GenCorpusOpts.Output)
+	if err != nil {
+		t.Fatalf("failed to read the counted words list: %v", err)
+	}
+	if string(data) != "BAD: 3\nGEK: 1\nNOP: 3\n" {
+		t.Errorf("counted words list '%s'", data)
+	}
+}
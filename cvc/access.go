@@ -0,0 +1,200 @@
+package cvc
+
+import (
+	"sort"
+)
+
+// ***************************************
+//           read accessors
+// ***************************************
+
+// the accessors only read, the sets and words lists they return are copies
+// so changing them does not change the group (or set, or map) rules state
+
+// Onset : return the word first consonant
+func (w *Word) Onset() string {
+	return w.c1
+}
+
+// Vowel : return the word vowel
+func (w *Word) Vowel() string {
+	return w.v
+}
+
+// Coda : return the word last consonant
+func (w *Word) Coda() string {
+	return w.c2
+}
+
+// Freq : return the word frequency
+func (w *Word) Freq() int {
+	return w.freq
+}
+
+// Metadata : return a copy of the word metadata, nil when there is none
+func (w *Word) Metadata() map[string]string {
+	return copyMeta(w.meta)
+}
+
+// Len : return the number of words in the set
+func (wset *WordSet) Len() int {
+	return wset.count
+}
+
+// Limit : return the number of words the set is made of
+func (wset *WordSet) Limit() int {
+	return wset.setlimit
+}
+
+// Full : return true when the set has all its words
+func (wset *WordSet) Full() bool {
+	return wset.count == wset.setlimit
+}
+
+// FreqCutoff : return the set frequency cutoff, 0 when there is none
+func (wset *WordSet) FreqCutoff() int {
+	return wset.freqcutoff
+}
+
+// FreqAbove : return the number of set words required above the cutoff
+func (wset *WordSet) FreqAbove() int {
+	return wset.freqabove
+}
+
+// Word : return the i'th (zero based) word of the set
+func (wset *WordSet) Word(i int) *Word {
+	return wset.list[i]
+}
+
+// Words : return a copy of the set words, in set order
+func (wset *WordSet) Words() WordList {
+	return append(WordList{}, wset.list...)
+}
+
+// Contains : return true when the word is in the set
+func (wset *WordSet) Contains(w *Word) bool {
+	return wset.list.contain(w)
+}
+
+// Each : call f with the set words in order, until f return false
+func (wset *WordSet) Each(f func(i int, w *Word) bool) {
+	for i, w := range wset.list {
+		if !f(i, w) {
+			return
+		}
+	}
+}
+
+// Len : return the number of sets in the group, the last may be unfinished
+func (wg *GroupSet) Len() int {
+	return wg.count
+}
+
+// Limit : return the number of sets the group is made of
+func (wg *GroupSet) Limit() int {
+	return wg.grouplimit
+}
+
+// SetLimit : return the number of words in each set of the group
+func (wg *GroupSet) SetLimit() int {
+	return wg.persetlimit
+}
+
+// FreqCutoff : return the sets frequency cutoff, 0 when there is none
+func (wg *GroupSet) FreqCutoff() int {
+	return wg.freqcutoff
+}
+
+// FreqAbove : return the number of words required above the cutoff in
+// each set
+func (wg *GroupSet) FreqAbove() int {
+	return wg.freqabove
+}
+
+// Full : return true when the group has all its sets and all the sets have
+// all their words
+func (wg *GroupSet) Full() bool {
+	if wg.count != wg.grouplimit {
+		return false
+	}
+	for _, set := range wg.list {
+		if set.count != wg.persetlimit {
+			return false
+		}
+	}
+	return true
+}
+
+// Set : return a copy of the i'th (zero based) set of the group
+func (wg *GroupSet) Set(i int) *WordSet {
+	return wg.list[i].CopySet()
+}
+
+// Sets : return a copy of the group sets, in order
+func (wg *GroupSet) Sets() WordSetList {
+	sets := make(WordSetList, 0, len(wg.list))
+	for _, set := range wg.list {
+		sets = append(sets, set.CopySet())
+	}
+	return sets
+}
+
+// Words : return the words of all the group sets, in order
+func (wg *GroupSet) Words() WordList {
+	var words WordList
+	for _, set := range wg.list {
+		words = append(words, set.list...)
+	}
+	return words
+}
+
+// WordCount : return the number of words placed in the group sets
+func (wg *GroupSet) WordCount() int {
+	count := 0
+	for _, set := range wg.list {
+		count += set.count
+	}
+	return count
+}
+
+// Contains : return true when the word is in one of the group sets
+func (wg *GroupSet) Contains(w *Word) bool {
+	return wg.setOf(w) != 0
+}
+
+// Each : call f with a copy of each group set in order, until f return false
+func (wg *GroupSet) Each(f func(i int, set *WordSet) bool) {
+	for i, set := range wg.list {
+		if !f(i, set.CopySet()) {
+			return
+		}
+	}
+}
+
+// Contains : return true when the word is in the map
+func (wmap *WordMap) Contains(w *Word) bool {
+	_, ok := wmap.lookup(w)
+	return ok
+}
+
+// Words : return the map words sorted by their name
+func (wmap *WordMap) Words() WordList {
+	words := make(WordList, 0, wmap.count)
+	wmap.each(func(id int, w *Word) {
+		words = append(words, w)
+	})
+	sort.Slice(words, func(i, j int) bool {
+		return words[i].actword < words[j].actword
+	})
+	return words
+}
+
+// Each : call f with the map words sorted by their name, until f return
+// false
+func (wmap *WordMap) Each(f func(w *Word) bool) {
+	for _, w := range wmap.Words() {
+		if !f(w) {
+			return
+		}
+	}
+}
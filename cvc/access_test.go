@@ -0,0 +1,122 @@
+package cvc
+
+import (
+	"testing"
+)
+
+func TestAccessWord(t *testing.T) {
+	w := NewWordMeta("SH", "A", "K", 12, map[string]string{"pos": "noun"})
+	if w.Onset() != "SH" || w.Vowel() != "A" || w.Coda() != "K" || w.Freq() != 12 {
+		t.Errorf("word %s accessors: %s %s %s %d", w, w.Onset(), w.Vowel(), w.Coda(), w.Freq())
+	}
+	meta := w.Metadata()
+	meta["pos"] = "verb"
+	if pos, _ := w.Meta("pos"); pos != "noun" {
+		t.Errorf("word metadata changed through its copy to %s", pos)
+	}
+	if NewWord("A", "B", "C", 1).Metadata() != nil {
+		t.Errorf("word without metadata return metadata")
+	}
+}
+
+func TestAccessSet(t *testing.T) {
+	_, cws := prepareTestData()
+
+	set := NewSetLimitFreq(3, 40, 1)
+	set.AddWord(cws[0]) // AAB:9
+	set.AddWord(cws[6]) // QER:69
+	if set.Len() != 2 || set.Limit() != 3 || set.Full() ||
+		set.FreqCutoff() != 40 || set.FreqAbove() != 1 {
+		t.Errorf("set %s accessors: %d %d %v %d %d", set, set.Len(), set.Limit(),
+			set.Full(), set.FreqCutoff(), set.FreqAbove())
+	}
+	if set.Word(1) != cws[6] || !set.Contains(cws[0]) || set.Contains(cws[1]) {
+		t.Errorf("set %s words access", set)
+	}
+
+	words := set.Words()
+	words[0] = cws[1]
+	if set.Word(0) != cws[0] {
+		t.Errorf("set %s changed through its words copy", set)
+	}
+
+	var seen WordList
+	set.Each(func(i int, w *Word) bool {
+		seen = append(seen, w)
+		return false
+	})
+	if len(seen) != 1 || seen[0] != cws[0] {
+		t.Errorf("set each did not stop after the first word: %s", seen.String())
+	}
+}
+
+func TestAccessGroup(t *testing.T) {
+	_, cws := prepareTestData()
+
+	group := NewGroupSetLimitFreq(2, 2, 0, 0)
+	for _, w := range []*Word{cws[0], cws[1], cws[2]} {
+		group.AddWord(w)
+	}
+	if group.Len() != 2 || group.Limit() != 2 || group.SetLimit() != 2 || group.Full() {
+		t.Errorf("group %s accessors: %d %d %d %v", group, group.Len(), group.Limit(),
+			group.SetLimit(), group.Full())
+	}
+	if words := group.Words(); len(words) != 3 || words[2] != cws[2] {
+		t.Errorf("group words %s", words.String())
+	}
+	if !group.Contains(cws[2]) || group.Contains(cws[3]) {
+		t.Errorf("group %s contains", group)
+	}
+
+	// the sets are copies, adding to them keep the group as is
+	group.Set(1).AddWord(cws[3])
+	group.Sets()[1].AddWord(cws[3])
+	group.Each(func(i int, set *WordSet) bool {
+		set.AddWord(cws[3])
+		return true
+	})
+	if group.Contains(cws[3]) || group.Set(1).Len() != 1 {
+		t.Errorf("group %s changed through its sets copies", group)
+	}
+
+	group.AddWord(cws[3])
+	if !group.Full() {
+		t.Errorf("group %s should be full", group)
+	}
+
+	// a full first set leave the group short of its second set
+	partial := NewGroupSetLimit(2, 2)
+	partial.AddWord(cws[0])
+	partial.AddWord(cws[1])
+	if partial.Full() {
+		t.Errorf("group %s with one of its two sets should not be full", partial)
+	}
+	if partial.WordCount() != 2 || group.WordCount() != 4 {
+		t.Errorf("groups word count %d and %d", partial.WordCount(), group.WordCount())
+	}
+}
+
+func TestAccessWordMap(t *testing.T) {
+	_, cws := prepareTestData()
+
+	wmap := NewWordMap()
+	for _, i := range []int{4, 0, 2} {
+		wmap.AddWord(cws[i])
+	}
+	words := wmap.Words()
+	if words.String() != "[AAB, FIG, LUM]" {
+		t.Errorf("map words %s are not sorted", words.String())
+	}
+	if !wmap.Contains(cws[2]) || wmap.Contains(cws[1]) {
+		t.Errorf("map %s contains", wmap)
+	}
+
+	var count int
+	wmap.Each(func(w *Word) bool {
+		count++
+		return w != cws[2]
+	})
+	if count != 2 {
+		t.Errorf("map each stopped after %d words, expected 2", count)
+	}
+}
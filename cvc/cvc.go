@@ -1,8 +1,10 @@
 package cvc
 
 import (
+	"errors"
 	"fmt"
-	"sort"
+	"math/bits"
+	"math/rand"
 	"strings"
 )
 
@@ -12,12 +14,14 @@ import (
 
 // Word - consonant/vowel/consonant actword bundle strucrt
 //  contain frequency for this word in the usage of the word
+//  and optional metadata (hebrew spelling, ipa, part of speech, tags ...)
 type Word struct {
 	c1      string
 	v       string
 	c2      string
 	actword string
 	freq    int
+	meta    map[string]string
 }
 
 // NewWord creating new CVC Word from given elements
@@ -31,6 +35,31 @@ func NewWord(c1 string, v string, c2 string, freq int) *Word {
 	return w
 }
 
+// NewWordMeta creating new CVC Word from given elements with metadata,
+//  the metadata is copied
+func NewWordMeta(c1 string, v string, c2 string, freq int, meta map[string]string) *Word {
+	w := NewWord(c1, v, c2, freq)
+	w.meta = copyMeta(meta)
+	return w
+}
+
+// Meta : return the word metadata value for key
+func (w *Word) Meta(key string) (string, bool) {
+	value, ok := w.meta[key]
+	return value, ok
+}
+
+func copyMeta(meta map[string]string) map[string]string {
+	if len(meta) == 0 {
+		return nil
+	}
+	newmeta := make(map[string]string, len(meta))
+	for key, value := range meta {
+		newmeta[key] = value
+	}
+	return newmeta
+}
+
 func (w *Word) dumpString() string {
 	return fmt.Sprintf("c[%s]:v[%s]:c[%s] [%s:%d]",
 		w.c1, w.v, w.c2, w.actword, w.freq)
@@ -178,7 +207,8 @@ func newSetConfigurable(consonantCount, vowelCount, setLimit int) *WordSet {
 	return newset
 }
 
-// NewSetLimit : TODO: fill me
+// NewSetLimit : return a new set of setlimit words, panic when setlimit is
+//  above 10 (the set room), use NewSetLimitErr to get an error instead
 func NewSetLimit(setlimit int) *WordSet {
 	newset := NewSet()
 	if setlimit > newset.setlimit {
@@ -188,10 +218,19 @@ func NewSetLimit(setlimit int) *WordSet {
 	return newset
 }
 
-// NewSetLimitFreq : TODO: fill me
+// NewSetLimitErr : like NewSetLimit, return ErrSetLimit instead of panicking
+func NewSetLimitErr(setlimit int) (*WordSet, error) {
+	if setlimit < 1 || setlimit > 10 {
+		return nil, newRuleError(ErrSetLimit, nil,
+			"set limit %d is not supported, should be 1 to 10", setlimit)
+	}
+	return NewSetLimit(setlimit), nil
+}
+
+// NewSetLimitFreq : return new set of setlimit words with frequency limits,
+//  the set has room for the consonants and vowels of any alphabet
 func NewSetLimitFreq(setlimit, fcutoff, fabove int) *WordSet {
-	newset := NewSet()
-	newset.setlimit = setlimit
+	newset := newSetConfigurable(2*setlimit, setlimit, setlimit)
 	newset.freqcutoff = fcutoff
 	newset.freqabove = fabove
 	return newset
@@ -206,18 +245,32 @@ func (wset *WordSet) StringWithFreq() string {
 	return wset.list.StringWithFreq()
 }
 
+// aboveCutoff : return true when the word frequency is above the cutoff, the
+//  compare of the word added to a set and of the feasibility check
+func aboveCutoff(w *Word, cutoff int) bool {
+	return w.freq > cutoff
+}
+
 func (wset *WordSet) freqCheckOk(w *Word) bool {
+	rule, _ := wset.freqCheck(w)
+	return rule == nil
+}
+
+// freqCheck : return ErrFrequencyQuota when the word breaks the set frequency
+//  quota, with the count of words above the cutoff the set would have
+func (wset *WordSet) freqCheck(w *Word) (rule error, above int) {
 	if wset.freqcutoff == 0 {
-		return true
+		return nil, 0
 	}
 
 	var acount, bcount int = 0, 0
-	if w.freq > wset.freqcutoff {
+	if aboveCutoff(w, wset.freqcutoff) {
 		acount++
 	} else {
 		bcount++
 	}
 
+	// the set words at the cutoff count as above it
 	for _, e := range wset.list {
 		if e.freq >= wset.freqcutoff {
 			acount++
@@ -226,57 +279,105 @@ func (wset *WordSet) freqCheckOk(w *Word) bool {
 		}
 	}
 	if acount > wset.freqabove {
-		return false
+		return ErrFrequencyQuota, acount
 	} else if acount+bcount == wset.setlimit && acount < wset.freqabove {
-		return false
+		return ErrFrequencyQuota, acount
 	}
 
-	return true
+	return nil, acount
 }
 
-// AddWord : TODO: fill me
-func (wset *WordSet) AddWord(w *Word) (added bool, full bool) {
+// checkWord : return the rule the word breaks (nil when it obeys them all)
+//  and the phoneme involved, without building an error since the search
+//  checks every candidate word
+func (wset *WordSet) checkWord(w *Word) (rule error, phoneme string, count int) {
 	if wset.count == wset.setlimit {
-		return false, true
+		return ErrSetFull, "", wset.setlimit
 	}
 	// check consonant validity : do not appear already in the list of cvc words
-	var fc int
-	for i, e := range wset.cMap {
-		fc = i
+	for _, e := range wset.cMap {
 		if e.consonant == "" {
 			break
 		}
-		if w.c1 == e.consonant {
-			if e.exist {
-				return false, false
-			}
-		}
-		if w.c2 == e.consonant {
-			if e.exist {
-				return false, false
-			}
+		if (w.c1 == e.consonant || w.c2 == e.consonant) && e.exist {
+			return ErrConsonantReused, e.consonant, 0
 		}
 	}
 
 	// check vowel validity : do not appear more then twice
-	var fv int
-	for i, e := range wset.vMap {
-		fv = i
-		if e.vowel == "" {
-			break
-		}
-		if w.v == e.vowel {
-			if e.count > 1 { // if its already 2 we dont want to add another one
-				return false, false
-			}
-			fv = i
-			break
+	for _, e := range wset.vMap {
+		if w.v == e.vowel && e.count > 1 { // if its already 2 we dont want to add another one
+			return ErrVowelQuotaExceeded, e.vowel, e.count
 		}
 	}
 
-	if !wset.freqCheckOk(w) {
+	rule, above := wset.freqCheck(w)
+	return rule, "", above
+}
+
+// CheckWord : return why the word can not be added to the set as a
+//  *RuleError, or nil when it can
+func (wset *WordSet) CheckWord(w *Word) error {
+	rule, phoneme, count := wset.checkWord(w)
+	switch rule {
+	case nil:
+		return nil
+	case ErrSetFull:
+		return newRuleError(rule, w, "set is full with %d words", count)
+	case ErrConsonantReused:
+		return newRuleError(rule, w, "consonant %s is already used in the set", phoneme)
+	case ErrVowelQuotaExceeded:
+		return newRuleError(rule, w, "vowel %s already appears %d times in the set", phoneme, count)
+	}
+	if count > wset.freqabove {
+		return newRuleError(rule, w, "more than %d words above the frequency cutoff %d",
+			wset.freqabove, wset.freqcutoff)
+	}
+	return newRuleError(rule, w, "less than %d words above the frequency cutoff %d",
+		wset.freqabove, wset.freqcutoff)
+}
+
+// AddWord : add the word to the set if it obeys the set rules (see CheckWord)
+func (wset *WordSet) AddWord(w *Word) (added bool, full bool) {
+	if wset.count == wset.setlimit {
+		return false, true
+	}
+	if rule, _, _ := wset.checkWord(w); rule != nil {
 		return false, false
 	}
+	wset.insert(w)
+
+	if wset.count == wset.setlimit {
+		return true, true
+	}
+	return true, false
+}
+
+// AddWordErr : add the word to the set, or return the rule it breaks (see
+//  CheckWord)
+func (wset *WordSet) AddWordErr(w *Word) error {
+	if err := wset.CheckWord(w); err != nil {
+		return err
+	}
+	wset.insert(w)
+	return nil
+}
+
+// insert : add a word already checked against the set rules
+func (wset *WordSet) insert(w *Word) {
+	// find the free consonant slot and the vowel slot
+	var fc int
+	for fc = range wset.cMap {
+		if wset.cMap[fc].consonant == "" {
+			break
+		}
+	}
+	var fv int
+	for fv = range wset.vMap {
+		if wset.vMap[fv].vowel == "" || wset.vMap[fv].vowel == w.v {
+			break
+		}
+	}
 
 	// update the counters for the consonants
 	wset.cMap[fc] = cbundle{w.c1, true}
@@ -293,11 +394,6 @@ func (wset *WordSet) AddWord(w *Word) (added bool, full bool) {
 	wset.count++
 	// and add to the list
 	wset.list = append(wset.list, w)
-
-	if wset.count == wset.setlimit {
-		return true, true
-	}
-	return true, false
 }
 
 // CopySet : TODO: fill me
@@ -366,6 +462,33 @@ func NewGroupSetLimitFreq(grouplimit, setlimit, fcutoff, fabove int) *GroupSet {
 	return newgroup
 }
 
+// NewGroupSetLimitFreqErr : like NewGroupSetLimitFreq, return ErrGroupLimit
+//  when the limits can not make a group
+func NewGroupSetLimitFreqErr(grouplimit, setlimit, fcutoff, fabove int) (*GroupSet, error) {
+	if grouplimit < 1 {
+		return nil, newRuleError(ErrGroupLimit, nil, "group of %d sets is not supported", grouplimit)
+	}
+	if err := checkSetLimits(setlimit, fcutoff, fabove); err != nil {
+		return nil, err
+	}
+	return NewGroupSetLimitFreq(grouplimit, setlimit, fcutoff, fabove), nil
+}
+
+// checkSetLimits : return ErrGroupLimit when the set limits can not make a
+//  set
+func checkSetLimits(setlimit, fcutoff, fabove int) error {
+	switch {
+	case setlimit < 1:
+		return newRuleError(ErrGroupLimit, nil, "set of %d words is not supported", setlimit)
+	case fcutoff < 0:
+		return newRuleError(ErrGroupLimit, nil, "frequency cutoff %d is negative", fcutoff)
+	case fcutoff > 0 && (fabove < 0 || fabove > setlimit):
+		return newRuleError(ErrGroupLimit, nil,
+			"%d words above the frequency cutoff do not fit a set of %d words", fabove, setlimit)
+	}
+	return nil
+}
+
 func (wg *GroupSet) String() string {
 	var out = string("\n")
 	for _, set := range wg.list {
@@ -405,28 +528,112 @@ func (wg *GroupSet) MaxSize() int {
 
 // AddWord : TODO: fill me
 func (wg *GroupSet) AddWord(w *Word) (added bool, full bool) {
+	rule := wg.addWord(w)
+	return rule == nil, rule == ErrGroupFull
+}
+
+// AddWordErr : add the word to the current set of the group, or return the
+//  rule it breaks as a *RuleError
+func (wg *GroupSet) AddWordErr(w *Word) error {
+	switch rule := wg.addWord(w); rule {
+	case nil:
+		return nil
+	case ErrGroupFull:
+		return newRuleError(rule, w, "group is full with %d sets", wg.grouplimit)
+	case ErrDuplicateInGroup:
+		return newRuleError(rule, w, "word %s is already used in set %d", w, wg.setOf(w))
+	}
+	return wg.list[wg.current].CheckWord(w)
+}
+
+// addWord : add the word to the current set, return the rule it breaks
+func (wg *GroupSet) addWord(w *Word) error {
 	// fmt.Printf("count: %d\n", wg.count)
 	switch {
-	case wg.count == wg.grouplimit && wg.list[wg.current].count == wg.persetlimit:
-		return false, true
-	case wg.count == 0:
-		fallthrough
-	case wg.list[wg.current].count == wg.persetlimit:
+	case wg.count > 0 && wg.list[wg.current].count < wg.persetlimit:
+		// keep filling the current set
+	case wg.current < wg.count-1:
+		// move on to the next unfinished (seeded) set
+		wg.current++
+	case wg.count == wg.grouplimit:
+		return ErrGroupFull
+	default:
 		// fmt.Printf("adding new set\n")
 		// wg.list = append(wg.list, NewSetLimit(wg.persetlimit))
 		wg.list = append(wg.list,
 			NewSetLimitFreq(wg.persetlimit, wg.freqcutoff, wg.freqabove))
 		wg.count++
+		wg.current = wg.count - 1 // count is one bases, current is zero based
 	}
 	// fmt.Printf("count: %d\n", wg.count)
-	wg.current = wg.count - 1 // count is one bases, current is zero based
-	for _, set := range wg.list {
+	if wg.setOf(w) != 0 {
+		return ErrDuplicateInGroup
+	}
+	set := wg.list[wg.current]
+	if rule, _, _ := set.checkWord(w); rule != nil {
+		return rule
+	}
+	set.insert(w)
+	return nil
+}
+
+// setOf : return the set (one based) holding the word, 0 when none does
+func (wg *GroupSet) setOf(w *Word) int {
+	for i, set := range wg.list {
 		if set.list.contain(w) {
-			return false, false
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// AddSet : add a set of fixed words (seed) to the group, the words must obey
+//  the set rules and must not appear in other sets of the group.
+//  complete sets are kept ahead of unfinished ones so AddWord continues with
+//  the first unfinished set
+func (wg *GroupSet) AddSet(words WordList) (added bool, full bool) {
+	err := wg.AddSetErr(words)
+	return err == nil, errors.Is(err, ErrGroupFull)
+}
+
+// AddSetErr : like AddSet, return the rule the first rejected word breaks as
+//  a *RuleError
+func (wg *GroupSet) AddSetErr(words WordList) error {
+	if wg.count == wg.grouplimit {
+		return newRuleError(ErrGroupFull, nil, "group is full with %d sets", wg.grouplimit)
+	}
+	newset := NewSetLimitFreq(wg.persetlimit, wg.freqcutoff, wg.freqabove)
+	for _, w := range words {
+		if n := wg.setOf(w); n != 0 {
+			return newRuleError(ErrDuplicateInGroup, w, "word %s is already used in set %d", w, n)
+		}
+		if err := newset.AddWordErr(w); err != nil {
+			return err
 		}
 	}
-	added, _ = wg.list[wg.current].AddWord(w)
-	return added, false
+
+	// find the first unfinished set
+	open := len(wg.list)
+	for i, set := range wg.list {
+		if set.count < wg.persetlimit {
+			open = i
+			break
+		}
+	}
+	if newset.count == wg.persetlimit {
+		wg.list = append(wg.list[:open],
+			append(WordSetList{newset}, wg.list[open:]...)...)
+		open++
+	} else {
+		wg.list = append(wg.list, newset)
+	}
+	wg.count++
+
+	wg.current = wg.count - 1
+	if open < wg.count {
+		wg.current = open
+	}
+	return nil
 }
 
 // CopyGroupSet : TODO: fill me
@@ -463,13 +670,13 @@ func (wg *GroupSet) Checkifavailable(wmap *WordMap) bool {
 	belowFreqMissing := belowFreqRequiredCount - belowFreqCurrentCount
 
 	var aboveAvailableCount, belowAvailableCount int
-	for _, v := range *wmap.GetCm() {
-		if v > wg.freqcutoff {
+	wmap.each(func(id int, w *Word) {
+		if aboveCutoff(w, wg.freqcutoff) {
 			aboveAvailableCount++
 		} else {
 			belowAvailableCount++
 		}
-	}
+	})
 	if belowAvailableCount < belowFreqMissing || aboveAvailableCount < aboveFreqMissing {
 		return false
 	}
@@ -481,93 +688,155 @@ func (wg *GroupSet) Checkifavailable(wmap *WordMap) bool {
 //           WordMap
 // ***************************************
 
-// WordMap : TODO: fill me
+// wordIndex : the dense ids of the words, shared by a map and its copies.
+//  the index only grows (by WordMap.AddWord), so maps sharing it must not
+//  add words concurrently
+type wordIndex struct {
+	words WordList
+	ids   map[*Word]int
+}
+
+func (index *wordIndex) add(w *Word) int {
+	id := len(index.words)
+	index.words = append(index.words, w)
+	index.ids[w] = id
+	return id
+}
+
+// WordMap : the words available for the group, a bitset over the word ids
+//  of the index so deleting a word and copying the map are cheap
 type WordMap struct {
-	cm    map[*Word]int
-	keys  WordList
+	index *wordIndex
+	avail []uint64
 	count int
 }
 
-// GetCm : TODO: fill me
+// GetCm : return the available words with their frequencies, the map is
+//  built on each call so changing it does not change the word map
 func (wmap *WordMap) GetCm() *map[*Word]int {
-	return &wmap.cm
+	cm := make(map[*Word]int, wmap.count)
+	wmap.each(func(id int, w *Word) {
+		cm[w] = w.freq
+	})
+	return &cm
 }
 
 // NewWordMap : TODO: fill me
 func NewWordMap() *WordMap {
 	var newmap = &WordMap{
-		cm: make(map[*Word]int),
-		// TODO: check if we need to initialize the key var ?
-		// keys: make([]*Word, 1),
+		index: &wordIndex{ids: make(map[*Word]int)},
 	}
 	return newmap
 }
 
-// CopyWordMap TODO: fill me
+// CopyWordMap : return a map sharing the words index, only the bitset is
+//  copied
 func (wmap *WordMap) CopyWordMap() *WordMap {
-	newmap := NewWordMap()
-	for k, v := range wmap.cm {
-		newmap.cm[k] = v
+	return &WordMap{
+		index: wmap.index,
+		avail: append([]uint64(nil), wmap.avail...),
+		count: wmap.count,
 	}
-	newmap.keys = make(WordList, len(wmap.keys))
-	copy(newmap.keys, wmap.keys)
-	newmap.count = wmap.count
+}
 
-	// the following line create error in the TestCvcMap
-	// newmap.keys = append(WordList{}, wmap.keys[0])
-	return newmap
+// lookup : return the word id and whether the word is available
+func (wmap *WordMap) lookup(w *Word) (int, bool) {
+	if wmap.index == nil {
+		return 0, false
+	}
+	id, ok := wmap.index.ids[w]
+	return id, ok && wmap.has(id)
+}
+
+func (wmap *WordMap) has(id int) bool {
+	return id/64 < len(wmap.avail) && wmap.avail[id/64]&(1<<uint(id%64)) != 0
+}
+
+// each : call f with the available words in id order
+func (wmap *WordMap) each(f func(id int, w *Word)) {
+	for i, word := range wmap.avail {
+		for ; word != 0; word &= word - 1 {
+			id := i*64 + bits.TrailingZeros64(word)
+			f(id, wmap.index.words[id])
+		}
+	}
 }
 
 // AddWord TODO: fill me
 func (wmap *WordMap) AddWord(w *Word) bool {
-	if _, w_already := wmap.cm[w]; w_already {
+	if wmap.index == nil {
+		wmap.index = &wordIndex{ids: make(map[*Word]int)}
+	}
+	id, ok := wmap.index.ids[w]
+	if !ok {
+		id = wmap.index.add(w)
+	} else if wmap.has(id) {
 		// print(w, " already in pool")
 		return false
 	}
-	wmap.keys = append(wmap.keys, w)
-	wmap.cm[w] = w.freq
+	for id/64 >= len(wmap.avail) {
+		wmap.avail = append(wmap.avail, 0)
+	}
+	wmap.avail[id/64] |= 1 << uint(id%64)
 	wmap.count++
 	return true
 }
 
+// AddWordErr : like AddWord, return ErrWordExists when the word is already
+//  in the map
+func (wmap *WordMap) AddWordErr(w *Word) error {
+	if !wmap.AddWord(w) {
+		return newRuleError(ErrWordExists, w, "word %s is already in the map", w)
+	}
+	return nil
+}
+
+// DelWordErr : like DelWord, return ErrWordNotFound when the word is not in
+//  the map
+func (wmap *WordMap) DelWordErr(w *Word) error {
+	if !wmap.DelWord(w) {
+		return newRuleError(ErrWordNotFound, w, "word %s is not in the map", w)
+	}
+	return nil
+}
+
 // DelWord TODO: fill me
 func (wmap *WordMap) DelWord(w *Word) bool {
-	if _, w_exist := wmap.cm[w]; !w_exist {
+	id, ok := wmap.lookup(w)
+	if !ok {
 		return false
 	}
-	for i, k := range wmap.keys {
-		if k == w {
-			wmap.keys = append(wmap.keys[:i], wmap.keys[i+1:]...)
-			break
-		}
-	}
-	delete(wmap.cm, w)
+	wmap.avail[id/64] &^= 1 << uint(id%64)
 	wmap.count--
 	return true
 }
 
-func (wmap *WordMap) String() string {
-	out := ""
-	sortedkeys := wmap.keys
-
-	sort.Slice(sortedkeys,
-		func(i, j int) bool {
-			// the order is A to Z, comparator left < right which result is -1
-			return strings.Compare(sortedkeys[i].actword,
-				sortedkeys[j].actword) == -1
-		})
+// Range : call f with the available words until f return false, starting at
+//  a random word like ranging over a go map does. f may delete words, the
+//  words deleted before they are reached are skipped
+func (wmap *WordMap) Range(f func(w *Word) bool) {
+	if wmap.index == nil || len(wmap.index.words) == 0 {
+		return
+	}
+	n := len(wmap.index.words)
+	start := rand.Intn(n)
+	for i := 0; i < n; i++ {
+		id := (start + i) % n
+		if wmap.has(id) && !f(wmap.index.words[id]) {
+			return
+		}
+	}
+}
 
-	// for k, v := range wmap.cm {
-	// 	out += fmt.Sprintf("%s:%d, ", k.String(), v)
-	// }
-	for _, k := range sortedkeys {
-		out += fmt.Sprintf("%s:%d, ", k.String(), wmap.cm[k])
+func (wmap *WordMap) String() string {
+	var out []string
+	for _, k := range wmap.Words() {
+		out = append(out, fmt.Sprintf("%s:%d", k.String(), k.freq))
 	}
-	return out[:len(out)-2]
+	return strings.Join(out, ", ")
 }
 
 // Size TODO: fill me
 func (wmap *WordMap) Size() int {
-	return len(wmap.cm)
+	return wmap.count
 }
-
@@ -518,3 +518,186 @@ func TestDumps(t *testing.T) {
 
 	group.DumpGroup()
 }
+
+func TestCvcGroupSetSeed(t *testing.T) {
+	_, cws := prepareTestData()
+
+	group := NewGroupSetLimit(3, 2)
+
+	// unfinished seed set, followed by a complete one
+	if added, _ := group.AddSet(WordList{cws[0]}); !added {
+		t.Errorf("seed set [%s] should be added to group %s", cws[0], group)
+	}
+	if added, _ := group.AddSet(WordList{cws[1], cws[2]}); !added {
+		t.Errorf("seed set [%s, %s] should be added to group %s",
+			cws[1], cws[2], group)
+	}
+	// reused word from another set
+	if added, _ := group.AddSet(WordList{cws[1]}); added {
+		t.Errorf("seed set [%s] should not be added to group %s", cws[1], group)
+	}
+	// set rules: consonant B appear in both words
+	if added, _ := group.AddSet(WordList{cws[0], cws[11]}); added {
+		t.Errorf("seed set [%s, %s] should not be added to group %s",
+			cws[0], cws[11], group)
+	}
+
+	testString := fmt.Sprintf("\n\t[%s, %s]\n\t[%s]\n", cws[1], cws[2], cws[0])
+	if group.String() != testString {
+		t.Errorf("group '%s', is not as test string '%s'", group.String(),
+			testString)
+	}
+
+	// the unfinished seed set is filled first, then a new set is opened
+	group.AddWord(cws[3])
+	group.AddWord(cws[4])
+	group.AddWord(cws[5])
+	testString = fmt.Sprintf("\n\t[%s, %s]\n\t[%s, %s]\n\t[%s, %s]\n",
+		cws[1], cws[2], cws[0], cws[3], cws[4], cws[5])
+	if group.String() != testString {
+		t.Errorf("group '%s', is not as test string '%s'", group.String(),
+			testString)
+	}
+
+	if _, full := group.AddWord(cws[6]); !full {
+		t.Errorf("group '%s' is full", group)
+	}
+	if _, full := group.AddSet(WordList{cws[6]}); !full {
+		t.Errorf("group '%s' is full", group)
+	}
+}
+
+func TestCVCwordMeta(t *testing.T) {
+	meta := map[string]string{"hebrew": "שור", "pos": "noun"}
+	w := NewWordMeta("SH", "O", "R", 120, meta)
+	meta["pos"] = "verb"
+
+	if v, ok := w.Meta("pos"); !ok || v != "noun" {
+		t.Errorf("word %s metadata pos is '%s', expected 'noun'", w, v)
+	}
+	if _, ok := w.Meta("ipa"); ok {
+		t.Errorf("word %s should not have ipa metadata", w)
+	}
+	if w.String() != "SHOR" {
+		t.Errorf("word '%s' is not as expected 'SHOR'", w)
+	}
+}
+
+func TestCvcSetAlphabet(t *testing.T) {
+	// 12 words set of an alphabet with 24 consonants and 6 vowels
+	vowels := []string{"A", "E", "I", "O", "U", "Y"}
+	set := NewSetLimitFreq(12, 0, 0)
+	for i := 0; i < 12; i++ {
+		w := NewWord(fmt.Sprintf("B%d", i), vowels[i/2], fmt.Sprintf("D%d", i), 1)
+		added, full := set.AddWord(w)
+		if !added {
+			t.Errorf("cvcword %s, should be joined to set %s", w, set)
+		}
+		if full != (i == 11) {
+			t.Errorf("set %s full is %v after %d words", set, full, i+1)
+		}
+	}
+	if added, _ := set.AddWord(NewWord("X", "Y", "Z", 1)); added {
+		t.Errorf("set %s is full", set)
+	}
+}
+
+func TestCvcSetCheckWord(t *testing.T) {
+	_, cws := prepareTestData()
+
+	reason := func(err error) string {
+		if err == nil {
+			return ""
+		}
+		return err.Error()
+	}
+	set := NewSetLimitFreq(4, 0, 0)
+	set.AddWord(cws[0]) // AAB
+	set.AddWord(cws[5]) // NAP
+	if act := reason(set.CheckWord(cws[12])); act != "consonant B is already used in the set" {
+		t.Errorf("check word %s '%s', expected consonant reason", cws[12], act)
+	}
+	if act := reason(set.CheckWord(cws[10])); act != "vowel A already appears 2 times in the set" {
+		t.Errorf("check word %s '%s', expected vowel reason", cws[10], act)
+	}
+	if act := reason(set.CheckWord(cws[1])); act != "" {
+		t.Errorf("check word %s '%s', expected no reason", cws[1], act)
+	}
+
+	set = NewSetLimitFreq(2, 40, 1)
+	set.AddWord(cws[5]) // NAP
+	if act := reason(set.CheckWord(cws[6])); act != "more than 1 words above the frequency cutoff 40" {
+		t.Errorf("check word %s '%s', expected frequency quota reason", cws[6], act)
+	}
+	set = NewSetLimitFreq(2, 40, 1)
+	set.AddWord(cws[0]) // AAB
+	if act := reason(set.CheckWord(cws[1])); act != "less than 1 words above the frequency cutoff 40" {
+		t.Errorf("check word %s '%s', expected frequency quota reason", cws[1], act)
+	}
+	set.AddWord(cws[5])
+	if act := reason(set.CheckWord(cws[1])); act != "set is full with 2 words" {
+		t.Errorf("check word %s '%s', expected full set reason", cws[1], act)
+	}
+}
+
+func TestCvcMapIndex(t *testing.T) {
+	var words WordList
+	for i := 0; i < 130; i++ {
+		words = append(words, NewWord(fmt.Sprintf("C%03d", i), "A", "B", i))
+	}
+
+	newmap := NewWordMap()
+	for _, w := range words {
+		newmap.AddWord(w)
+	}
+	if newmap.Size() != 130 || len(newmap.avail) != 3 {
+		t.Errorf("map of 130 words has size %d and %d bitset words", newmap.Size(), len(newmap.avail))
+	}
+	full := newmap.String()
+
+	// copies share the index and only own their bitset
+	copymap := newmap.CopyWordMap()
+	if copymap.index != newmap.index {
+		t.Errorf("map copy does not share the words index")
+	}
+	copymap.DelWord(words[64])
+	copymap.DelWord(words[129])
+	if !newmap.Contains(words[64]) || copymap.Contains(words[64]) || copymap.Size() != 128 {
+		t.Errorf("delete in the map copy changed the map or was lost")
+	}
+
+	// a word added back keep its id, so the map is as before
+	copymap.AddWord(words[129])
+	copymap.AddWord(words[64])
+	if copymap.String() != full || len(copymap.index.words) != 130 {
+		t.Errorf("map with its words added back is not as the original")
+	}
+
+	// range visit every word once, also when deleting the visited words
+	seen := make(map[*Word]int)
+	copymap.Range(func(w *Word) bool {
+		seen[w]++
+		copymap.DelWord(w)
+		return true
+	})
+	if len(seen) != 130 || copymap.Size() != 0 || copymap.String() != "" {
+		t.Errorf("range visited %d words, map left with %d", len(seen), copymap.Size())
+	}
+	for w, n := range seen {
+		if n != 1 {
+			t.Errorf("range visited %s %d times", w, n)
+		}
+	}
+
+	var zero WordMap
+	if zero.DelWord(words[0]) || zero.Contains(words[0]) || zero.Size() != 0 {
+		t.Errorf("zero map is not empty")
+	}
+	zero.Range(func(w *Word) bool {
+		t.Errorf("range over the zero map visited %s", w)
+		return true
+	})
+	if !zero.AddWord(words[0]) || zero.Size() != 1 {
+		t.Errorf("zero map did not take a word")
+	}
+}
@@ -0,0 +1,43 @@
+package cvc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// the rules a word (or a set) can break, returned wrapped in a RuleError by
+// the ...Err variants of the constructors and mutators, test them with
+// errors.Is
+var (
+	ErrSetLimit           = errors.New("set limit not supported")
+	ErrGroupLimit         = errors.New("group limits not supported")
+	ErrSetFull            = errors.New("set is full")
+	ErrGroupFull          = errors.New("group is full")
+	ErrConsonantReused    = errors.New("consonant already used in the set")
+	ErrVowelQuotaExceeded = errors.New("vowel already used twice in the set")
+	ErrFrequencyQuota     = errors.New("set frequency quota broken")
+	ErrDuplicateInGroup   = errors.New("word already used in another set of the group")
+	ErrWordExists         = errors.New("word already in the map")
+	ErrWordNotFound       = errors.New("word not in the map")
+)
+
+// RuleError : a rule broken by a word, Err is one of the Err values above and
+// Word the offending word (nil for the constructors limits)
+type RuleError struct {
+	Err  error
+	Word *Word
+	msg  string
+}
+
+func newRuleError(rule error, w *Word, f string, v ...interface{}) *RuleError {
+	return &RuleError{Err: rule, Word: w, msg: fmt.Sprintf(f, v...)}
+}
+
+func (e *RuleError) Error() string {
+	return e.msg
+}
+
+// Unwrap : return the broken rule
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,118 @@
+package cvc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrLimits(t *testing.T) {
+	if _, err := NewSetLimitErr(11); !errors.Is(err, ErrSetLimit) {
+		t.Errorf("set of 11 words: %v, expected ErrSetLimit", err)
+	}
+	if set, err := NewSetLimitErr(3); err != nil || set.setlimit != 3 {
+		t.Errorf("set of 3 words: %v", err)
+	}
+
+	for _, c := range [][4]int{{0, 2, 0, 0}, {2, 0, 0, 0}, {2, 2, -1, 0}, {2, 2, 40, 3}} {
+		if _, err := NewGroupSetLimitFreqErr(c[0], c[1], c[2], c[3]); !errors.Is(err, ErrGroupLimit) {
+			t.Errorf("group %v: %v, expected ErrGroupLimit", c, err)
+		}
+	}
+	if _, err := NewGroupSetLimitFreqErr(2, 4, 40, 2); err != nil {
+		t.Errorf("group 2x4: %v", err)
+	}
+}
+
+func TestErrSetRules(t *testing.T) {
+	_, cws := prepareTestData()
+
+	set := NewSetLimitFreq(3, 0, 0)
+	if err := set.AddWordErr(cws[0]); err != nil { // AAB
+		t.Errorf("add %s: %v", cws[0], err)
+	}
+	err := set.AddWordErr(cws[12]) // KEB
+	if !errors.Is(err, ErrConsonantReused) {
+		t.Errorf("add %s: %v, expected ErrConsonantReused", cws[12], err)
+	}
+	var rerr *RuleError
+	if !errors.As(err, &rerr) || rerr.Word != cws[12] {
+		t.Errorf("add %s: %v is not a rule error of the word", cws[12], err)
+	}
+	if err := set.AddWordErr(cws[5]); err != nil { // NAP
+		t.Errorf("add %s: %v", cws[5], err)
+	}
+	if err := set.AddWordErr(cws[10]); !errors.Is(err, ErrVowelQuotaExceeded) { // RAZ
+		t.Errorf("add %s: %v, expected ErrVowelQuotaExceeded", cws[10], err)
+	}
+	if err := set.AddWordErr(cws[1]); err != nil { // CED
+		t.Errorf("add %s: %v", cws[1], err)
+	}
+	if err := set.AddWordErr(cws[2]); !errors.Is(err, ErrSetFull) { // FIG
+		t.Errorf("add %s: %v, expected ErrSetFull", cws[2], err)
+	}
+	if set.count != 3 {
+		t.Errorf("set %s should hold 3 words", set)
+	}
+
+	set = NewSetLimitFreq(2, 40, 1)
+	set.AddWord(cws[5]) // NAP:59
+	if err := set.AddWordErr(cws[6]); !errors.Is(err, ErrFrequencyQuota) {
+		t.Errorf("add %s: %v, expected ErrFrequencyQuota", cws[6], err)
+	}
+}
+
+func TestErrGroupRules(t *testing.T) {
+	_, cws := prepareTestData()
+
+	group := NewGroupSetLimitFreq(1, 2, 0, 0)
+	if err := group.AddWordErr(cws[0]); err != nil { // AAB
+		t.Errorf("add %s: %v", cws[0], err)
+	}
+	if err := group.AddWordErr(cws[0]); !errors.Is(err, ErrDuplicateInGroup) {
+		t.Errorf("add %s again: %v, expected ErrDuplicateInGroup", cws[0], err)
+	}
+	if err := group.AddWordErr(cws[12]); !errors.Is(err, ErrConsonantReused) { // KEB
+		t.Errorf("add %s: %v, expected ErrConsonantReused", cws[12], err)
+	}
+	if err := group.AddWordErr(cws[1]); err != nil { // CED
+		t.Errorf("add %s: %v", cws[1], err)
+	}
+	if err := group.AddWordErr(cws[2]); !errors.Is(err, ErrGroupFull) { // FIG
+		t.Errorf("add %s: %v, expected ErrGroupFull", cws[2], err)
+	}
+
+	group = NewGroupSetLimitFreq(2, 2, 0, 0)
+	if err := group.AddSetErr(WordList{cws[0], cws[1]}); err != nil {
+		t.Errorf("add set: %v", err)
+	}
+	if err := group.AddSetErr(WordList{cws[2], cws[1]}); !errors.Is(err, ErrDuplicateInGroup) {
+		t.Errorf("add set: %v, expected ErrDuplicateInGroup", err)
+	}
+	if err := group.AddSetErr(WordList{cws[0]}); !errors.Is(err, ErrDuplicateInGroup) {
+		t.Errorf("add set: %v, expected ErrDuplicateInGroup", err)
+	}
+	if err := group.AddSetErr(WordList{cws[2], cws[3]}); err != nil {
+		t.Errorf("add set: %v", err)
+	}
+	if err := group.AddSetErr(WordList{cws[4]}); !errors.Is(err, ErrGroupFull) {
+		t.Errorf("add set: %v, expected ErrGroupFull", err)
+	}
+}
+
+func TestErrWordMap(t *testing.T) {
+	_, cws := prepareTestData()
+
+	wmap := NewWordMap()
+	if err := wmap.AddWordErr(cws[0]); err != nil {
+		t.Errorf("add %s: %v", cws[0], err)
+	}
+	if err := wmap.AddWordErr(cws[0]); !errors.Is(err, ErrWordExists) {
+		t.Errorf("add %s again: %v, expected ErrWordExists", cws[0], err)
+	}
+	if err := wmap.DelWordErr(cws[1]); !errors.Is(err, ErrWordNotFound) {
+		t.Errorf("delete %s: %v, expected ErrWordNotFound", cws[1], err)
+	}
+	if err := wmap.DelWordErr(cws[0]); err != nil {
+		t.Errorf("delete %s: %v", cws[0], err)
+	}
+}
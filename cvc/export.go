@@ -0,0 +1,64 @@
+package cvc
+
+// ***************************************
+//           Placement
+// ***************************************
+
+// Placement : a word placed in a set, one row of a flat export
+type Placement struct {
+	Set      int // set index in the group, one based
+	Position int // word position in the set, one based
+	Word     string
+	Onset    string
+	Vowel    string
+	Coda     string
+	Freq     int
+	Above    bool              // frequency is above the set frequency cutoff
+	Meta     map[string]string // copy of the word metadata
+}
+
+// Band : "above" or "below" the frequency cutoff
+func (p Placement) Band() string {
+	if p.Above {
+		return "above"
+	}
+	return "below"
+}
+
+// WordPlacement : return the word as a placement outside of any set, its
+//  band is taken against fcutoff
+func WordPlacement(w *Word, fcutoff int) Placement {
+	return Placement{
+		Word:  w.actword,
+		Onset: w.c1,
+		Vowel: w.v,
+		Coda:  w.c2,
+		Freq:  w.freq,
+		Above: aboveCutoff(w, fcutoff),
+		Meta:  copyMeta(w.meta),
+	}
+}
+
+// Placements : return the set words placement, in set order
+func (wset *WordSet) Placements() []Placement {
+	var out []Placement
+	for i, w := range wset.list {
+		p := WordPlacement(w, wset.freqcutoff)
+		p.Set = 1
+		p.Position = i + 1
+		out = append(out, p)
+	}
+	return out
+}
+
+// Placements : return the words placement of all the group sets, in order
+func (wg *GroupSet) Placements() []Placement {
+	var out []Placement
+	for i, set := range wg.list {
+		for _, p := range set.Placements() {
+			p.Set = i + 1
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,51 @@
+package cvc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPlacements(t *testing.T) {
+	_, cws := prepareTestData()
+
+	group := NewGroupSetLimitFreq(2, 2, 40, 1)
+	group.AddWord(cws[0])
+	group.AddWord(cws[5])
+	group.AddWord(cws[1])
+
+	expected := []string{
+		"1:1:AAB:A:A:B:9:below",
+		"1:2:NAP:N:A:P:59:above",
+		"2:1:CED:C:E:D:19:below",
+	}
+	pl := group.Placements()
+	if len(pl) != len(expected) {
+		t.Fatalf("group %s has %d placements, expected %d",
+			group.StringWithFreq(), len(pl), len(expected))
+	}
+	for i, p := range pl {
+		act := fmt.Sprintf("%d:%d:%s:%s:%s:%s:%d:%s",
+			p.Set, p.Position, p.Word, p.Onset, p.Vowel, p.Coda, p.Freq, p.Band())
+		if act != expected[i] {
+			t.Errorf("placement %d '%s', is not as expected '%s'", i, act, expected[i])
+		}
+	}
+
+	if len(NewSet().Placements()) != 0 {
+		t.Errorf("empty set should have no placements")
+	}
+}
+
+func TestPlacementsMeta(t *testing.T) {
+	set := NewSet()
+	set.AddWord(NewWordMeta("SH", "O", "R", 120, map[string]string{"hebrew": "שור"}))
+
+	pl := set.Placements()
+	if pl[0].Meta["hebrew"] != "שור" {
+		t.Errorf("placement metadata '%v' has no hebrew spelling", pl[0].Meta)
+	}
+	pl[0].Meta["hebrew"] = ""
+	if v, _ := set.list[0].Meta("hebrew"); v != "שור" {
+		t.Errorf("placement metadata change leaked to word %s", set.list[0])
+	}
+}
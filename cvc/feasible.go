@@ -0,0 +1,112 @@
+package cvc
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ***************************************
+//           Feasibility
+// ***************************************
+
+// Infeasible : return the necessary condition for completing the group that
+//  the words of wmap break, or an empty string when none is broken.
+//  passing all the conditions does not mean the group can be completed, it
+//  only rules out searching for groups which can not be
+func (wg *GroupSet) Infeasible(wmap *WordMap) string {
+	// the sets left to fill and the words available to them, the words of
+	// unfinished (seeded) sets count as available
+	sets := wg.grouplimit
+	var pool WordList
+	for _, set := range wg.list {
+		if set.count == wg.persetlimit {
+			sets--
+		} else {
+			pool = append(pool, set.list...)
+		}
+	}
+	wmap.each(func(id int, w *Word) {
+		pool = append(pool, w)
+	})
+	if sets <= 0 {
+		return ""
+	}
+
+	words := wg.persetlimit
+	if len(pool) < sets*words {
+		return fmt.Sprintf("%d sets of %d words need %d words, only %d are available",
+			sets, words, sets*words, len(pool))
+	}
+
+	if wg.freqcutoff != 0 {
+		var above int
+		for _, w := range pool {
+			if aboveCutoff(w, wg.freqcutoff) {
+				above++
+			}
+		}
+		if above < sets*wg.freqabove {
+			return fmt.Sprintf("%d sets need %d words above the frequency cutoff %d, only %d are available",
+				sets, sets*wg.freqabove, wg.freqcutoff, above)
+		}
+		below := len(pool) - above
+		if below < sets*(words-wg.freqabove) {
+			return fmt.Sprintf("%d sets need %d words below the frequency cutoff %d, only %d are available",
+				sets, sets*(words-wg.freqabove), wg.freqcutoff, below)
+		}
+	}
+
+	vowels := make(map[string]int)
+	consonants := make(map[string]int)
+	perWord := 2 // distinct consonants in each word
+	for _, w := range pool {
+		vowels[w.v]++
+		consonants[w.c1]++
+		if w.c2 != w.c1 {
+			consonants[w.c2]++
+		} else {
+			perWord = 1
+		}
+	}
+
+	// a set holds a vowel at most twice, so each vowel must fill the words
+	// the other vowels can not
+	if words > 2*len(vowels) {
+		return fmt.Sprintf("sets of %d words need at least %d vowels, the words have %d",
+			words, (words+1)/2, len(vowels))
+	}
+	if perSet := words - 2*(len(vowels)-1); perSet > 0 {
+		for _, v := range sortedKeys(vowels) {
+			if vowels[v] < sets*perSet {
+				return fmt.Sprintf("vowel %s is in %d words, %d sets need %d (%d in each set)",
+					v, vowels[v], sets, sets*perSet, perSet)
+			}
+		}
+	}
+
+	// a set holds a consonant at most once, so when the set words need as
+	// many consonants as the words have, every consonant must be in every set
+	if words*perWord > len(consonants) {
+		return fmt.Sprintf("sets of %d words need at least %d consonants, the words have %d",
+			words, words*perWord, len(consonants))
+	}
+	if words*perWord > len(consonants)-1 {
+		for _, c := range sortedKeys(consonants) {
+			if consonants[c] < sets {
+				return fmt.Sprintf("consonant %s is in %d words, %d sets need it in each set",
+					c, consonants[c], sets)
+			}
+		}
+	}
+
+	return ""
+}
+
+func sortedKeys(m map[string]int) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,108 @@
+package cvc
+
+import (
+	"testing"
+)
+
+func feasibleTestMap(words ...*Word) *WordMap {
+	wmap := NewWordMap()
+	for _, w := range words {
+		wmap.AddWord(w)
+	}
+	return wmap
+}
+
+func TestInfeasible(t *testing.T) {
+	_, cws := prepareTestData()
+
+	if act := NewGroupSetLimitFreq(2, 2, 40, 1).Infeasible(feasibleTestMap(cws...)); act != "" {
+		t.Errorf("feasible group infeasible: %s", act)
+	}
+
+	expected := "7 sets of 2 words need 14 words, only 13 are available"
+	if act := NewGroupSetLimit(7, 2).Infeasible(feasibleTestMap(cws...)); act != expected {
+		t.Errorf("too few words: expected '%s', actual '%s'", expected, act)
+	}
+
+	expected = "2 sets need 4 words above the frequency cutoff 100, only 3 are available"
+	if act := NewGroupSetLimitFreq(2, 2, 100, 2).Infeasible(feasibleTestMap(cws...)); act != expected {
+		t.Errorf("too few words above the cutoff: expected '%s', actual '%s'", expected, act)
+	}
+
+	expected = "sets of 3 words need at least 2 vowels, the words have 1"
+	if act := NewGroupSetLimit(1, 3).Infeasible(feasibleTestMap(cws[0], cws[5], cws[10])); act != expected {
+		t.Errorf("too few vowels: expected '%s', actual '%s'", expected, act)
+	}
+
+	expected = "vowel E is in 3 words, 2 sets need 4 (2 in each set)"
+	wmap := feasibleTestMap(
+		NewWord("B", "A", "C", 1), NewWord("D", "A", "F", 1), NewWord("G", "A", "H", 1),
+		NewWord("J", "E", "K", 1), NewWord("L", "A", "M", 1), NewWord("N", "E", "P", 1),
+		NewWord("Q", "E", "R", 1), NewWord("S", "A", "T", 1))
+	if act := NewGroupSetLimit(2, 4).Infeasible(wmap); act != expected {
+		t.Errorf("vowel in too few words: expected '%s', actual '%s'", expected, act)
+	}
+
+	expected = "sets of 2 words need at least 4 consonants, the words have 2"
+	wmap = feasibleTestMap(NewWord("B", "A", "C", 1), NewWord("C", "E", "B", 1))
+	if act := NewGroupSetLimit(1, 2).Infeasible(wmap); act != expected {
+		t.Errorf("too few consonants: expected '%s', actual '%s'", expected, act)
+	}
+
+	expected = "consonant B is in 1 words, 2 sets need it in each set"
+	wmap = feasibleTestMap(
+		NewWord("B", "A", "C", 1), NewWord("D", "E", "F", 1),
+		NewWord("C", "I", "D", 1), NewWord("F", "O", "D", 1), NewWord("D", "U", "C", 1))
+	if act := NewGroupSetLimit(2, 2).Infeasible(wmap); act != expected {
+		t.Errorf("consonant in too few words: expected '%s', actual '%s'", expected, act)
+	}
+}
+
+func TestInfeasibleSeeded(t *testing.T) {
+	_, cws := prepareTestData()
+
+	group := NewGroupSetLimit(2, 2)
+	group.AddSet(WordList{cws[0], cws[1]})
+	wmap := feasibleTestMap(cws[2], cws[3])
+	if act := group.Infeasible(wmap); act != "" {
+		t.Errorf("seeded group with a complete set is infeasible: %s", act)
+	}
+	wmap.DelWord(cws[3])
+	if act := group.Infeasible(wmap); act != "1 sets of 2 words need 2 words, only 1 are available" {
+		t.Errorf("seeded group missing a word, infeasible '%s'", act)
+	}
+}
+
+func TestFreqAtCutoff(t *testing.T) {
+	at := NewWord("B", "A", "C", 40)
+	high := NewWord("D", "E", "F", 50)
+	low := NewWord("G", "I", "H", 30)
+
+	// a word at the cutoff is below it when added to a set, and for the
+	// feasibility check, but above it once in the set
+	set := NewSetLimitFreq(2, 40, 1)
+	if err := set.AddWordErr(at); err != nil {
+		t.Fatalf("adding %s at the cutoff: %v", at, err)
+	}
+	if err := set.AddWordErr(high); err == nil {
+		t.Errorf("set %s accepted %s above the cutoff next to %s", set, high, at)
+	}
+
+	set = NewSetLimitFreq(2, 40, 1)
+	set.AddWord(low)
+	if err := set.AddWordErr(at); err == nil {
+		t.Errorf("set %s with no word above the cutoff accepted %s", set, at)
+	}
+
+	group := NewGroupSetLimitFreq(1, 2, 40, 1)
+	if act := group.Infeasible(feasibleTestMap(at, low)); act !=
+		"1 sets need 1 words above the frequency cutoff 40, only 0 are available" {
+		t.Errorf("words at the cutoff counted above, infeasible '%s'", act)
+	}
+	if act := group.Infeasible(feasibleTestMap(at, high)); act != "" {
+		t.Errorf("words around the cutoff infeasible: %s", act)
+	}
+	if WordPlacement(at, 40).Above {
+		t.Errorf("word %s placed above the cutoff 40", at)
+	}
+}
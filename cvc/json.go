@@ -0,0 +1,188 @@
+package cvc
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ***************************************
+//           JSON encoding
+// ***************************************
+
+type jsonWord struct {
+	Word string            `json:"word"`
+	C1   string            `json:"c1"`
+	V    string            `json:"v"`
+	C2   string            `json:"c2"`
+	Freq int               `json:"freq"`
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+// jsonWordSet : the set words, the limits are only written for a set on its
+// own (the group ones apply to the group sets)
+type jsonWordSet struct {
+	Words      WordList `json:"words"`
+	MaxWords   int      `json:"max_words,omitempty"`
+	FreqCutoff int      `json:"freq_cutoff,omitempty"`
+	FreqAbove  int      `json:"freq_above,omitempty"`
+}
+
+type jsonGroupSet struct {
+	Sets       []jsonWordSet `json:"sets"`
+	MaxSets    int           `json:"max_sets"`
+	MaxWords   int           `json:"max_words"`
+	FreqCutoff int           `json:"freq_cutoff"`
+	FreqAbove  int           `json:"freq_above"`
+}
+
+// MarshalJSON encode the word with its c1/v/c2 parts and frequency
+func (w *Word) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonWord{w.actword, w.c1, w.v, w.c2, w.freq, w.meta})
+}
+
+// MarshalJSON encode the set words
+func (wset *WordSet) MarshalJSON() ([]byte, error) {
+	words := wset.list
+	if words == nil {
+		words = WordList{}
+	}
+	return json.Marshal(jsonWordSet{words, wset.setlimit, wset.freqcutoff, wset.freqabove})
+}
+
+// MarshalJSON encode the group sets along with the group limits
+func (wg *GroupSet) MarshalJSON() ([]byte, error) {
+	sets := make([]jsonWordSet, 0, len(wg.list))
+	for _, set := range wg.list {
+		words := set.list
+		if words == nil {
+			words = WordList{}
+		}
+		sets = append(sets, jsonWordSet{Words: words})
+	}
+	return json.Marshal(jsonGroupSet{
+		sets, wg.grouplimit, wg.persetlimit, wg.freqcutoff, wg.freqabove})
+}
+
+// MarshalJSON encode the map words sorted by their name
+func (wmap *WordMap) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonWordSet{Words: wmap.Words()})
+}
+
+// ***************************************
+//           JSON decoding
+// ***************************************
+
+// the decoded sets, groups and maps are built by adding their words one by
+// one, so they obey the same rules as the ones built by the search (the
+// consonants, vowels and counts are never read)
+
+// DecodeWord : decode a json word into a new word, its c1/v/c2 parts must
+// make up the word
+func DecodeWord(data []byte) (*Word, error) {
+	var jw jsonWord
+	if err := json.Unmarshal(data, &jw); err != nil {
+		return nil, err
+	}
+	if jw.C1 == "" || jw.V == "" || jw.C2 == "" {
+		return nil, fmt.Errorf("word '%s' is missing its c1/v/c2 parts", jw.Word)
+	}
+	if jw.Word != "" && jw.Word != jw.C1+jw.V+jw.C2 {
+		return nil, fmt.Errorf("word '%s' is not made of '%s', '%s' and '%s'",
+			jw.Word, jw.C1, jw.V, jw.C2)
+	}
+	return NewWordMeta(jw.C1, jw.V, jw.C2, jw.Freq, jw.Meta), nil
+}
+
+// UnmarshalJSON decode a word in place, only for a word which is not in a
+// set, group or map yet (they keep their words by pointer, so overwriting
+// one of them corrupt them), use DecodeWord to get a new word
+func (w *Word) UnmarshalJSON(data []byte) error {
+	neww, err := DecodeWord(data)
+	if err != nil {
+		return err
+	}
+	*w = *neww
+	return nil
+}
+
+// UnmarshalJSON decode each word of the list into a new word (see
+// DecodeWord), a null word is an error
+func (wl *WordList) UnmarshalJSON(data []byte) error {
+	var list []json.RawMessage
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	words := make(WordList, 0, len(list))
+	for i, raw := range list {
+		if string(raw) == "null" {
+			return fmt.Errorf("word %d is null", i+1)
+		}
+		w, err := DecodeWord(raw)
+		if err != nil {
+			return fmt.Errorf("word %d: %w", i+1, err)
+		}
+		words = append(words, w)
+	}
+	*wl = words
+	return nil
+}
+
+// UnmarshalJSON decode a set written on its own, with its limits
+func (wset *WordSet) UnmarshalJSON(data []byte) error {
+	var js jsonWordSet
+	if err := json.Unmarshal(data, &js); err != nil {
+		return err
+	}
+	if err := checkSetLimits(js.MaxWords, js.FreqCutoff, js.FreqAbove); err != nil {
+		return err
+	}
+	newset := NewSetLimitFreq(js.MaxWords, js.FreqCutoff, js.FreqAbove)
+	for i, w := range js.Words {
+		if err := newset.AddWordErr(w); err != nil {
+			return fmt.Errorf("word %d: %w", i+1, err)
+		}
+	}
+	*wset = *newset
+	return nil
+}
+
+// UnmarshalJSON decode a group, complete or not
+func (wg *GroupSet) UnmarshalJSON(data []byte) error {
+	var jg jsonGroupSet
+	if err := json.Unmarshal(data, &jg); err != nil {
+		return err
+	}
+	newgroup, err := NewGroupSetLimitFreqErr(
+		jg.MaxSets, jg.MaxWords, jg.FreqCutoff, jg.FreqAbove)
+	if err != nil {
+		return err
+	}
+	for i, set := range jg.Sets {
+		if err := newgroup.AddSetErr(set.Words); err != nil {
+			return fmt.Errorf("set %d: %w", i+1, err)
+		}
+	}
+	*wg = *newgroup
+	return nil
+}
+
+// UnmarshalJSON decode a map, a word may appear once
+func (wmap *WordMap) UnmarshalJSON(data []byte) error {
+	var js jsonWordSet
+	if err := json.Unmarshal(data, &js); err != nil {
+		return err
+	}
+	// the decoded words are all new, so the same word twice is not caught by
+	// the map itself
+	names := make(map[string]bool)
+	newmap := NewWordMap()
+	for _, w := range js.Words {
+		if names[w.actword] {
+			return newRuleError(ErrWordExists, w, "word %s is already in the map", w)
+		}
+		names[w.actword] = true
+		newmap.AddWord(w)
+	}
+	*wmap = *newmap
+	return nil
+}
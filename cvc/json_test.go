@@ -0,0 +1,179 @@
+package cvc
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestJSONWord(t *testing.T) {
+	w := NewWord("SH", "O", "R", 55)
+
+	data, err := json.Marshal(w)
+	if err != nil {
+		t.Fatalf("failed to marshal word %s: %v", w, err)
+	}
+	expected := `{"word":"SHOR","c1":"SH","v":"O","c2":"R","freq":55}`
+	if string(data) != expected {
+		t.Errorf("word json '%s', is not as expected '%s'", data, expected)
+	}
+}
+
+func TestJSONGroupSet(t *testing.T) {
+	_, cws := prepareTestData()
+
+	group := NewGroupSetLimitFreq(2, 2, 40, 1)
+	group.AddWord(cws[0])
+	group.AddWord(cws[5])
+	group.AddWord(cws[1])
+
+	data, err := json.Marshal(group)
+	if err != nil {
+		t.Fatalf("failed to marshal group %s: %v", group, err)
+	}
+	expected := `{"sets":[` +
+		`{"words":[{"word":"AAB","c1":"A","v":"A","c2":"B","freq":9},` +
+		`{"word":"NAP","c1":"N","v":"A","c2":"P","freq":59}]},` +
+		`{"words":[{"word":"CED","c1":"C","v":"E","c2":"D","freq":19}]}],` +
+		`"max_sets":2,"max_words":2,"freq_cutoff":40,"freq_above":1}`
+	if string(data) != expected {
+		t.Errorf("group json '%s', is not as expected '%s'", data, expected)
+	}
+
+	empty, _ := json.Marshal(NewSet())
+	if string(empty) != `{"words":[],"max_words":10}` {
+		t.Errorf("empty set json '%s', is not as expected '%s'", empty, `{"words":[],"max_words":10}`)
+	}
+}
+
+func TestJSONDecodeWord(t *testing.T) {
+	_, cws := prepareTestData()
+
+	set := NewSetLimit(2)
+	set.AddWord(cws[0])
+	data := []byte(`{"word":"SHOR","c1":"SH","v":"O","c2":"R","freq":55}`)
+	w, err := DecodeWord(data)
+	if err != nil {
+		t.Fatalf("failed to decode word '%s': %v", data, err)
+	}
+	if w.String() != "SHOR" || w.Freq() != 55 || w == cws[0] {
+		t.Errorf("word '%s' decoded as %s", data, w)
+	}
+	if set.Word(0) != cws[0] || set.Word(0).String() != "AAB" {
+		t.Errorf("set %s changed by decoding a new word", set)
+	}
+
+	if _, err := DecodeWord([]byte(`{"word":"SHOR","c1":"SH"}`)); err == nil {
+		t.Errorf("decoded a word missing its parts")
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	_, cws := prepareTestData()
+
+	w := NewWordMeta("SH", "O", "R", 55, map[string]string{"ipa": "ʃoʁ"})
+	data, _ := json.Marshal(w)
+	var neww Word
+	if err := json.Unmarshal(data, &neww); err != nil {
+		t.Fatalf("failed to unmarshal word '%s': %v", data, err)
+	}
+	if neww.DumpString() != w.DumpString() || neww.Freq() != 55 {
+		t.Errorf("word %s decoded as %s", w.DumpString(), neww.DumpString())
+	}
+	if ipa, _ := neww.Meta("ipa"); ipa != "ʃoʁ" {
+		t.Errorf("word metadata decoded as '%s'", ipa)
+	}
+
+	set := NewSetLimitFreq(3, 40, 1)
+	set.AddWord(cws[0])
+	set.AddWord(cws[5])
+	data, _ = json.Marshal(set)
+	var newset WordSet
+	if err := json.Unmarshal(data, &newset); err != nil {
+		t.Fatalf("failed to unmarshal set '%s': %v", data, err)
+	}
+	if newset.DumpSet() != set.DumpSet() {
+		t.Errorf("set\n%s\ndecoded as\n%s", set.DumpSet(), newset.DumpSet())
+	}
+	if added, _ := newset.AddWord(cws[10]); added {
+		t.Errorf("decoded set %s took a third A vowel word", newset.String())
+	}
+
+	// a seeded group, with a complete set after an unfinished one
+	group := NewGroupSetLimitFreq(3, 2, 40, 1)
+	group.AddSet(WordList{cws[0]})
+	group.AddSet(WordList{cws[1], cws[6]})
+	group.AddWord(cws[7])
+	data, _ = json.Marshal(group)
+	var newgroup GroupSet
+	if err := json.Unmarshal(data, &newgroup); err != nil {
+		t.Fatalf("failed to unmarshal group '%s': %v", data, err)
+	}
+	if newgroup.DumpGroup() != group.DumpGroup() {
+		t.Errorf("group\n%s\ndecoded as\n%s", group.DumpGroup(), newgroup.DumpGroup())
+	}
+	if again, _ := json.Marshal(&newgroup); string(again) != string(data) {
+		t.Errorf("group json '%s' encoded again as '%s'", data, again)
+	}
+
+	wmap := NewWordMap()
+	for _, i := range []int{4, 0, 2} {
+		wmap.AddWord(cws[i])
+	}
+	data, _ = json.Marshal(wmap)
+	var newmap WordMap
+	if err := json.Unmarshal(data, &newmap); err != nil {
+		t.Fatalf("failed to unmarshal map '%s': %v", data, err)
+	}
+	if newmap.String() != wmap.String() || newmap.Size() != 3 {
+		t.Errorf("map %s decoded as %s", wmap, &newmap)
+	}
+}
+
+func TestJSONDecodeRules(t *testing.T) {
+	word := func(c1, v, c2 string) string {
+		return `{"c1":"` + c1 + `","v":"` + v + `","c2":"` + c2 + `","freq":50}`
+	}
+
+	if err := json.Unmarshal([]byte(`{"word":"XYZ","c1":"A","v":"B","c2":"C"}`), &Word{}); err == nil {
+		t.Errorf("decoded a word not made of its phonemes")
+	}
+	if err := json.Unmarshal([]byte(`{"word":"ABC","c1":"A"}`), &Word{}); err == nil {
+		t.Errorf("decoded a word missing its phonemes")
+	}
+
+	data := `{"words":[` + word("A", "E", "B") + `,` + word("B", "I", "C") + `],"max_words":3}`
+	if err := json.Unmarshal([]byte(data), &WordSet{}); !errors.Is(err, ErrConsonantReused) {
+		t.Errorf("set reusing a consonant decoded with %v", err)
+	}
+	data = `{"words":[` + word("A", "E", "B") + `,` + word("C", "I", "D") + `],"max_words":1}`
+	if err := json.Unmarshal([]byte(data), &WordSet{}); !errors.Is(err, ErrSetFull) {
+		t.Errorf("set with too many words decoded with %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"words":[]}`), &WordSet{}); !errors.Is(err, ErrGroupLimit) {
+		t.Errorf("set with no words limit decoded with %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"words":[null],"max_words":2}`), &WordSet{}); err == nil {
+		t.Errorf("decoded a set with a null word")
+	}
+
+	data = `{"sets":[{"words":[` + word("A", "E", "B") + `]},{"words":[` + word("A", "E", "B") +
+		`]}],"max_sets":2,"max_words":2}`
+	if err := json.Unmarshal([]byte(data), &GroupSet{}); !errors.Is(err, ErrDuplicateInGroup) {
+		t.Errorf("group with a word in two sets decoded with %v", err)
+	}
+	data = `{"sets":[{"words":[]},{"words":[]}],"max_sets":1,"max_words":2}`
+	if err := json.Unmarshal([]byte(data), &GroupSet{}); !errors.Is(err, ErrGroupFull) {
+		t.Errorf("group with too many sets decoded with %v", err)
+	}
+	data = `{"sets":[{"words":[` + word("A", "E", "B") + `,` + word("C", "I", "D") +
+		`]}],"max_sets":1,"max_words":2,"freq_cutoff":40,"freq_above":1}`
+	if err := json.Unmarshal([]byte(data), &GroupSet{}); !errors.Is(err, ErrFrequencyQuota) {
+		t.Errorf("group breaking the frequency quota decoded with %v", err)
+	}
+
+	data = `{"words":[` + word("A", "E", "B") + `,` + word("A", "E", "B") + `]}`
+	if err := json.Unmarshal([]byte(data), &WordMap{}); !errors.Is(err, ErrWordExists) {
+		t.Errorf("word map with a word twice decoded with %v", err)
+	}
+}
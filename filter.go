@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// word filter file format, one rule per line ('#' start a comment):
+//
+//	exclude JOD TAL      drop the listed words
+//	exclude c2=SH,TZ     drop any word with coda SH or TZ
+//	include v=A JESH     keep only words matching an include rule
+//
+// the roles are c1 (or onset), v (or vowel) and c2 (or coda), a line may mix
+// words and role patterns, the word matches the rule if any of them match.
+// exclude rules win over include rules.
+
+var filterRoles = map[string]string{
+	"c1": "c1", "onset": "c1",
+	"v": "v", "vowel": "v",
+	"c2": "c2", "coda": "c2",
+}
+
+type filterRule struct {
+	line    int
+	text    string
+	include bool
+	words   map[string]bool
+	roles   map[string]map[string]bool
+	removed int
+}
+
+func (r *filterRule) match(c1, v, c2 string) bool {
+	return r.words[c1+v+c2] || r.roles["c1"][c1] || r.roles["v"][v] || r.roles["c2"][c2]
+}
+
+type wordFilter struct {
+	fname       string
+	rules       []*filterRule
+	includeOnly bool
+	notIncluded int
+}
+
+func newFilterRule(line int, text string) (*filterRule, error) {
+	fields := strings.Fields(text)
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("line %d: rule '%s' has no words or patterns", line, text)
+	}
+
+	r := &filterRule{
+		line:  line,
+		text:  strings.Join(fields, " "),
+		words: make(map[string]bool),
+		roles: make(map[string]map[string]bool),
+	}
+	switch fields[0] {
+	case "include":
+		r.include = true
+	case "exclude":
+	default:
+		return nil, fmt.Errorf("line %d: unknown action '%s', expected include or exclude",
+			line, fields[0])
+	}
+
+	for _, item := range fields[1:] {
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) == 1 {
+			r.words[item] = true
+			continue
+		}
+		role, ok := filterRoles[kv[0]]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown role '%s', expected c1, v or c2",
+				line, kv[0])
+		}
+		if r.roles[role] == nil {
+			r.roles[role] = make(map[string]bool)
+		}
+		for _, p := range strings.Split(kv[1], ",") {
+			if p != "" {
+				r.roles[role][p] = true
+			}
+		}
+	}
+	return r, nil
+}
+
+// loadFilter read the filter rules from fname
+func loadFilter(fname string) (*wordFilter, error) {
+	f := &wordFilter{fname: fname}
+	lines, err := getLinesFromFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range lines {
+		if c := strings.Index(line, "#"); c != -1 {
+			line = line[:c]
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		r, err := newFilterRule(i+1, line)
+		if err != nil {
+			return nil, err
+		}
+		if r.include {
+			f.includeOnly = true
+		}
+		f.rules = append(f.rules, r)
+	}
+	return f, nil
+}
+
+// keep report whether the word made of c1, v and c2 pass the filter, and
+// count the removed word against the rule which dropped it
+func (f *wordFilter) keep(c1, v, c2 string) bool {
+	if f == nil {
+		return true
+	}
+	for _, r := range f.rules {
+		if !r.include && r.match(c1, v, c2) {
+			r.removed++
+			return false
+		}
+	}
+	if !f.includeOnly {
+		return true
+	}
+	for _, r := range f.rules {
+		if r.include && r.match(c1, v, c2) {
+			return true
+		}
+	}
+	f.notIncluded++
+	return false
+}
+
+// summary return how many words each rule removed
+func (f *wordFilter) summary() string {
+	out := fmt.Sprintf("filter '%s':\n", f.fname)
+	for _, r := range f.rules {
+		if r.include {
+			continue
+		}
+		out += fmt.Sprintf("\tline %d: %s: removed %d words\n", r.line, r.text, r.removed)
+	}
+	if f.includeOnly {
+		out += fmt.Sprintf("\tinclude rules: removed %d words\n", f.notIncluded)
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilterRule(t *testing.T) {
+	r, err := newFilterRule(3, "exclude JOD   TAL")
+	if err != nil {
+		t.Fatalf("failed to parse the words rule: %v", err)
+	}
+	if r.include || r.text != "exclude JOD TAL" {
+		t.Errorf("words rule parsed as include %v text '%s'", r.include, r.text)
+	}
+	if !r.match("J", "O", "D") || !r.match("T", "A", "L") || r.match("D", "O", "J") {
+		t.Errorf("words rule %s does not match its words only", r.text)
+	}
+
+	r, err = newFilterRule(4, "include onset=SH,TZ v=A")
+	if err != nil {
+		t.Fatalf("failed to parse the roles rule: %v", err)
+	}
+	if !r.include {
+		t.Errorf("rule %s is not an include rule", r.text)
+	}
+	if !r.match("SH", "O", "R") || !r.match("TZ", "I", "K") || !r.match("B", "A", "D") {
+		t.Errorf("roles rule %s does not match its onsets and vowel", r.text)
+	}
+	if r.match("B", "O", "SH") {
+		t.Errorf("roles rule %s match the SH coda", r.text)
+	}
+
+	r, err = newFilterRule(5, "exclude coda=M, BOR")
+	if err != nil {
+		t.Fatalf("failed to parse the mixed rule: %v", err)
+	}
+	if !r.match("B", "A", "M") || !r.match("B", "O", "R") || r.match("M", "A", "B") {
+		t.Errorf("mixed rule %s does not match its coda and word only", r.text)
+	}
+
+	if _, err = newFilterRule(6, "exclude"); err == nil ||
+		err.Error() != "line 6: rule 'exclude' has no words or patterns" {
+		t.Errorf("rule with no items: %v", err)
+	}
+	if _, err = newFilterRule(7, "drop JOD"); err == nil ||
+		!strings.Contains(err.Error(), "line 7: unknown action 'drop'") {
+		t.Errorf("rule with an unknown action: %v", err)
+	}
+	if _, err = newFilterRule(8, "exclude c3=B"); err == nil ||
+		!strings.Contains(err.Error(), "line 8: unknown role 'c3'") {
+		t.Errorf("rule with an unknown role: %v", err)
+	}
+}
+
+func TestFilterKeep(t *testing.T) {
+	f, err := loadFilter(writeTestFile(t, "filter.txt", `# drop the SH coda words
+exclude c2=SH   # and this is a comment
+
+include v=A
+include JOD
+exclude BAD
+`))
+	if err != nil {
+		t.Fatalf("failed to load filter: %v", err)
+	}
+
+	if !f.keep("L", "A", "M") || !f.keep("J", "O", "D") {
+		t.Errorf("filter does not keep the included words")
+	}
+	// exclude rules win over include rules
+	if f.keep("B", "A", "D") || f.keep("R", "A", "SH") {
+		t.Errorf("filter keep the excluded words")
+	}
+	if f.keep("L", "O", "M") {
+		t.Errorf("filter keep a word no include rule match")
+	}
+
+	expected := "filter '" + f.fname + "':\n" +
+		"\tline 2: exclude c2=SH: removed 1 words\n" +
+		"\tline 6: exclude BAD: removed 1 words\n" +
+		"\tinclude rules: removed 1 words\n"
+	if actual := f.summary(); actual != expected {
+		t.Errorf("filter summary: expected '%s', actual '%s'", expected, actual)
+	}
+
+	var none *wordFilter
+	if !none.keep("L", "O", "M") {
+		t.Errorf("no filter should keep every word")
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"unicode"
+
+	"github.com/gilwo/wordscvc/cvc"
+)
+
+// html worksheet output, one printable page per set, the document is self
+// contained (inline style, no external assets)
+
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>CVC word sets</title>
+<style>
+body { margin: 0; font-family: "Arial", "David", sans-serif; }
+.page { padding: 2cm; break-after: page; page-break-after: always; }
+.page h1 { margin: 0 0 1cm; font-size: 14pt; font-weight: normal; color: #555; }
+.page ol { margin: 0; padding: 0; list-style: none; }
+.page li { font-size: 40pt; line-height: 1.5; }
+.freq { font-size: 14pt; color: #777; }
+.translit, .ipa { font-size: 16pt; color: #777; }
+.above { font-size: 20pt; color: #c00; }
+</style>
+</head>
+<body>
+`
+
+const htmlFooter = `</body>
+</html>
+`
+
+var htmlPage = template.Must(template.New("page").Parse(
+	`<div class="page" dir="{{.Dir}}">
+<h1>group {{.Group}} &middot; set {{.Set}}</h1>
+<ol>
+{{- range .Words}}
+<li>{{.Text}}
+{{- if .Translit}} <span class="translit" dir="ltr">{{.Translit}}</span>{{end}}
+{{- if .IPA}} <span class="ipa" dir="ltr">/{{.IPA}}/</span>{{end}}
+{{- if $.ShowMarks}}{{if .Above}} <span class="above" title="above frequency cutoff">&#9733;</span>{{end}}{{end}}
+{{- if $.ShowFreq}} <span class="freq">({{.Freq}})</span>{{end}}</li>
+{{- end}}
+</ol>
+</div>
+`))
+
+type htmlWord struct {
+	Text     string
+	Translit string
+	IPA      string
+	Freq     int
+	Above    bool
+}
+
+type htmlPageData struct {
+	Group     int
+	Set       int
+	Dir       string
+	ShowFreq  bool
+	ShowMarks bool
+	Words     []htmlWord
+}
+
+// isHebrew report whether s is written in hebrew script
+func isHebrew(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Hebrew, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatHTML render the group sets as worksheet pages, pages with hebrew
+// script are laid out right to left
+func formatHTML(opts *flagOpts, index int, group *cvc.GroupSet) (string, error) {
+	var pages []*htmlPageData
+	for _, p := range group.Placements() {
+		if len(pages) < p.Set {
+			pages = append(pages, &htmlPageData{
+				Group:     index,
+				Set:       p.Set,
+				Dir:       "ltr",
+				ShowFreq:  opts.HTMLFreq,
+				ShowMarks: opts.HTMLMarks,
+			})
+		}
+		page := pages[p.Set-1]
+		w := htmlWord{Text: p.Word, Freq: p.Freq, Above: p.Above}
+		if hebrew, ok := p.Meta["hebrew"]; ok && opts.Hebrew {
+			w.Text, w.Translit = hebrew, p.Word
+		}
+		if opts.IPA {
+			w.IPA = p.Meta["ipa"]
+		}
+		page.Words = append(page.Words, w)
+		if isHebrew(w.Text) {
+			page.Dir = "rtl"
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, page := range pages {
+		if err := htmlPage.Execute(&buf, page); err != nil {
+			return "", err
+		}
+	}
+	return buf.String(), nil
+}
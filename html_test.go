@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLPages(t *testing.T) {
+	group := testGroup()
+	opts := flagOpts{}
+	opts.Format = "html"
+	out, err := formatHTML(&opts, 2, group)
+	if err != nil {
+		t.Fatalf("failed to format the html group: %v", err)
+	}
+	if strings.Count(out, `<div class="page"`) != 1 {
+		t.Errorf("html group has not one page per set '%s'", out)
+	}
+	for _, part := range []string{
+		`<div class="page" dir="ltr">`, `<h1>group 2 &middot; set 1</h1>`,
+		"<li>SHOR</li>", "<li>JAD</li>"} {
+		if !strings.Contains(out, part) {
+			t.Errorf("html group is missing '%s': '%s'", part, out)
+		}
+	}
+
+	opts.Hebrew, opts.HTMLFreq, opts.HTMLMarks = true, true, true
+	if out, _ = formatHTML(&opts, 2, group); !strings.Contains(out, `<div class="page" dir="rtl">`) ||
+		!strings.Contains(out, `<li>שור <span class="translit" dir="ltr">SHOR</span>`+
+			` <span class="above" title="above frequency cutoff">&#9733;</span> <span class="freq">(75)</span></li>`) {
+		t.Errorf("hebrew html group with the marks and frequencies '%s'", out)
+	}
+}
+
+func TestHTMLHeader(t *testing.T) {
+	opts := flagOpts{}
+	opts.Format = "html"
+	header, err := formatHeader(&opts)
+	if err != nil || !strings.HasPrefix(header, "<!DOCTYPE html>") {
+		t.Errorf("html header '%s', %v", header, err)
+	}
+	if footer := formatFooter(&opts); footer != htmlFooter {
+		t.Errorf("html footer '%s'", footer)
+	}
+
+	opts.AppendResult = true
+	if _, err = formatHeader(&opts); err == nil {
+		t.Errorf("html output can be appended to")
+	}
+}
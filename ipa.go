@@ -0,0 +1,30 @@
+package main
+
+// ipa transcription of the phonemes, the symbols are given as an optional
+// third field in the consonants and vowels files ("SH: 1 ʃ")
+
+// wordIPA compose the word transcription from its phonemes symbols, ok is
+// false when one of them has no symbol
+func (t *wordTables) wordIPA(c1, v, c2 string) (string, bool) {
+	s1, ok1 := t.ipa[c1]
+	sv, okv := t.ipa[v]
+	s2, ok2 := t.ipa[c2]
+	return s1 + sv + s2, ok1 && okv && ok2
+}
+
+// showPhoneme return the phoneme for messages, with its ipa symbol when asked
+func (t *wordTables) showPhoneme(p string) string {
+	if s, ok := t.ipa[p]; ok && t.showIPA {
+		return p + " /" + s + "/"
+	}
+	return p
+}
+
+// showWord return the word for outputs and messages, with its ipa
+// transcription when asked
+func (t *wordTables) showWord(word, ipa string) string {
+	if ipa != "" && t.showIPA {
+		return word + " /" + ipa + "/"
+	}
+	return word
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestIPASymbols(t *testing.T) {
+	ipa := make(map[string]string)
+	consonants, err := getMap(writeTestFile(t, "consonants.txt", "SH: 1 ʃ\nR: 1 ʁ\nM: 1\n"), ipa)
+	if err != nil {
+		t.Fatalf("failed to load the consonants: %v", err)
+	}
+	if len(consonants) != 3 || len(ipa) != 2 || ipa["SH"] != "ʃ" {
+		t.Errorf("consonants %v loaded with the ipa symbols %v", consonants, ipa)
+	}
+
+	tables := &wordTables{ipa: ipa}
+	tables.ipa["O"] = "o"
+	if word, ok := tables.wordIPA("SH", "O", "R"); !ok || word != "ʃoʁ" {
+		t.Errorf("SHOR transcribed as '%s', %v", word, ok)
+	}
+	if _, ok := tables.wordIPA("M", "O", "R"); ok {
+		t.Errorf("MOR transcribed with no symbol for M")
+	}
+
+	if p := tables.showPhoneme("SH"); p != "SH" {
+		t.Errorf("phoneme shown as '%s' with the ipa off", p)
+	}
+	tables.showIPA = true
+	if p := tables.showPhoneme("SH"); p != "SH /ʃ/" {
+		t.Errorf("phoneme shown as '%s'", p)
+	}
+	if p := tables.showPhoneme("M"); p != "M" {
+		t.Errorf("phoneme with no symbol shown as '%s'", p)
+	}
+	if w := tables.showWord("SHOR", "ʃoʁ"); w != "SHOR /ʃoʁ/" {
+		t.Errorf("word shown as '%s'", w)
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// lexicon formats, selected by the words file extension:
+//
+//	text (any other extension), one "WORD: frequency" per line
+//	.json, an array of objects:
+//	  [{"word": "SHOR", "freq": 120, "hebrew": "שור", "ipa": "ʃor",
+//	    "pos": "noun", "tags": ["animal"]}, ...]
+//	.csv / .tsv, a header row naming the columns, then one word per row:
+//	  word,freq,hebrew,ipa,pos,tags
+//
+// word (or translit) and freq (or frequency) are required, c1/v/c2 may give
+// the word split explicitly, any other field is kept as the word metadata
+
+// lexEntry : lexicon word with its metadata
+type lexEntry struct {
+	line      int
+	word      string
+	freq      int
+	c1, v, c2 string
+	meta      map[string]string
+}
+
+var lexWordKeys = []string{"word", "translit"}
+var lexFreqKeys = []string{"freq", "frequency"}
+
+// loadLexicon read the words of fname according to its format
+func loadLexicon(fname string) ([]lexEntry, error) {
+	switch strings.ToLower(filepath.Ext(fname)) {
+	case ".json":
+		return loadJSONLexicon(fname)
+	case ".csv":
+		return loadCSVLexicon(fname, ',')
+	case ".tsv":
+		return loadCSVLexicon(fname, '\t')
+	}
+	return loadTextLexicon(fname)
+}
+
+// parseWordLine parse a "WORD: number" line
+func parseWordLine(line string) (string, int, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 2 || !strings.HasSuffix(fields[0], ":") {
+		return "", 0, fmt.Errorf("'%s' is not in the 'WORD: number' format", line)
+	}
+	n, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return "", 0, fmt.Errorf("'%s' has a non numeric value '%s'", line, fields[1])
+	}
+	return strings.TrimSuffix(fields[0], ":"), n, nil
+}
+
+func loadTextLexicon(fname string) ([]lexEntry, error) {
+	var entries []lexEntry
+	lines, err := getLinesFromFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		w, f, err := parseWordLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", fname, i+1, err)
+		}
+		entries = append(entries, lexEntry{line: i + 1, word: w, freq: f})
+	}
+	return entries, nil
+}
+
+// newLexEntry build an entry from the named fields of a json object or a
+// csv row
+func newLexEntry(line int, fields map[string]string) (lexEntry, error) {
+	e := lexEntry{line: line, meta: make(map[string]string)}
+	var freq string
+	for k, v := range fields {
+		switch {
+		case inList(k, lexWordKeys):
+			e.word = v
+		case inList(k, lexFreqKeys):
+			freq = v
+		case k == "c1":
+			e.c1 = v
+		case k == "v":
+			e.v = v
+		case k == "c2":
+			e.c2 = v
+		case v != "":
+			e.meta[k] = v
+		}
+	}
+
+	if e.word == "" {
+		return e, fmt.Errorf("entry has no word")
+	}
+	f, err := strconv.Atoi(freq)
+	if err != nil {
+		return e, fmt.Errorf("word '%s' has a non numeric frequency '%s'", e.word, freq)
+	}
+	e.freq = f
+	if (e.c1 != "" || e.v != "" || e.c2 != "") && e.c1+e.v+e.c2 != e.word {
+		return e, fmt.Errorf("word '%s' split c1/v/c2 '%s/%s/%s' does not match",
+			e.word, e.c1, e.v, e.c2)
+	}
+	return e, nil
+}
+
+func loadJSONLexicon(fname string) ([]lexEntry, error) {
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	var objs []map[string]interface{}
+	if err = json.Unmarshal(data, &objs); err != nil {
+		return nil, fmt.Errorf("%s: %v", fname, err)
+	}
+
+	var entries []lexEntry
+	for i, obj := range objs {
+		fields := make(map[string]string)
+		for k, v := range obj {
+			switch v := v.(type) {
+			case []interface{}:
+				var items []string
+				for _, item := range v {
+					items = append(items, fmt.Sprint(item))
+				}
+				fields[k] = strings.Join(items, ",")
+			case float64:
+				fields[k] = strconv.FormatFloat(v, 'f', -1, 64)
+			case nil:
+			default:
+				fields[k] = fmt.Sprint(v)
+			}
+		}
+		e, err := newLexEntry(i+1, fields)
+		if err != nil {
+			return nil, fmt.Errorf("%s: entry %d: %v", fname, i+1, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func loadCSVLexicon(fname string, comma rune) ([]lexEntry, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.Comma = comma
+	r.Comment = '#'
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%s: %v", fname, err)
+	}
+	for c := range header {
+		header[c] = strings.ToLower(strings.TrimSpace(header[c]))
+	}
+
+	var entries []lexEntry
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %v", fname, err)
+		}
+		line, _ := r.FieldPos(0)
+
+		fields := make(map[string]string)
+		for c, v := range rec {
+			fields[header[c]] = strings.TrimSpace(v)
+		}
+		e, err := newLexEntry(line, fields)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", fname, line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// split return the entry c1/v/c2 parts, when they are not given the word is
+// split around its vowel (the second or third letter) by the vowels given
+func (e lexEntry) split(vowels map[string]int) (c1, v, c2 string, err error) {
+	if e.c1+e.v+e.c2 != "" {
+		return e.c1, e.v, e.c2, nil
+	}
+	w := e.word
+	if len(w) > 2 {
+		if _, ok := vowels[w[1:2]]; ok {
+			return w[0:1], w[1:2], w[2:], nil
+		}
+	}
+	if len(w) > 3 {
+		return w[0:2], w[2:3], w[3:], nil
+	}
+	return "", "", "", fmt.Errorf("word '%s' is too short to split into c1/v/c2", w)
+}
+
+// freqScaleUnit is the fixed point unit of the per-million and zipf scales,
+// their values are kept as integers in hundredths so the rare words do not
+// round to 0 and zipf keep its fraction
+const freqScaleUnit = 100
+
+// scaleFrequencies convert the entries raw counts to scale:
+//
+//	raw, the counts as given
+//	per-million, the count per million of the lexicon total count, in
+//	  hundredths (1.5 per million is 150)
+//	zipf, log10 of the per-million rate plus 3 (1 is very rare, 7 is very
+//	  common) in hundredths (zipf 4.25 is 425), 0 for words never counted
+//	rank, the percentage of the words less common than the word
+//
+// all the scales keep higher as more common, so the cutoff compare the same
+func scaleFrequencies(entries []lexEntry, scale string) {
+	var total int
+	for _, e := range entries {
+		total += e.freq
+	}
+	switch scale {
+	case "per-million", "zipf":
+		if total == 0 {
+			return
+		}
+		for i, e := range entries {
+			pm := float64(e.freq) * 1e6 / float64(total)
+			if scale == "per-million" {
+				entries[i].freq = int(math.Round(pm * freqScaleUnit))
+			} else if e.freq > 0 {
+				entries[i].freq = int(math.Round((math.Log10(pm) + 3) * freqScaleUnit))
+			}
+		}
+	case "rank":
+		counts := make([]int, len(entries))
+		for i, e := range entries {
+			counts[i] = e.freq
+		}
+		sort.Ints(counts)
+		// the least common word is at 0 and the most common at 100, a
+		// single word is the most common
+		for i, e := range entries {
+			if len(entries) == 1 {
+				entries[i].freq = 100
+				continue
+			}
+			less := sort.SearchInts(counts, e.freq)
+			entries[i].freq = less * 100 / (len(entries) - 1)
+		}
+	}
+}
+
+// writeLexicon write the entries to fname in the format of its extension
+// (see loadLexicon), the metadata is kept in the json and csv formats
+func writeLexicon(fname string, entries []lexEntry) error {
+	var buf bytes.Buffer
+	split := false
+	var metaKeys []string
+	for _, e := range entries {
+		split = split || e.c1+e.v+e.c2 != ""
+		for k := range e.meta {
+			if !inList(k, metaKeys) {
+				metaKeys = append(metaKeys, k)
+			}
+		}
+	}
+	sort.Strings(metaKeys)
+
+	switch strings.ToLower(filepath.Ext(fname)) {
+	case ".json":
+		var objs []map[string]interface{}
+		for _, e := range entries {
+			obj := map[string]interface{}{"word": e.word, "freq": e.freq}
+			if split {
+				obj["c1"], obj["v"], obj["c2"] = e.c1, e.v, e.c2
+			}
+			for k, v := range e.meta {
+				obj[k] = v
+			}
+			objs = append(objs, obj)
+		}
+		data, err := json.MarshalIndent(objs, "", "  ")
+		if err != nil {
+			return err
+		}
+		buf.Write(append(data, '\n'))
+	case ".csv", ".tsv":
+		w := csv.NewWriter(&buf)
+		if strings.ToLower(filepath.Ext(fname)) == ".tsv" {
+			w.Comma = '\t'
+		}
+		header := []string{"word", "freq"}
+		if split {
+			header = append(header, "c1", "v", "c2")
+		}
+		w.Write(append(header, metaKeys...))
+		for _, e := range entries {
+			rec := []string{e.word, strconv.Itoa(e.freq)}
+			if split {
+				rec = append(rec, e.c1, e.v, e.c2)
+			}
+			for _, k := range metaKeys {
+				rec = append(rec, e.meta[k])
+			}
+			w.Write(rec)
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+	default:
+		for _, e := range entries {
+			fmt.Fprintf(&buf, "%s: %d\n", e.word, e.freq)
+		}
+	}
+	return ioutil.WriteFile(fname, buf.Bytes(), 0644)
+}
+
+func inList(s string, list []string) bool {
+	for _, e := range list {
+		if s == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// entriesString render the entries as "line:word:freq:c1/v/c2:meta" for the
+// compare
+func entriesString(entries []lexEntry) string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, fmt.Sprintf("%d:%s:%d:%s/%s/%s:%v",
+			e.line, e.word, e.freq, e.c1, e.v, e.c2, e.meta))
+	}
+	return strings.Join(out, " ")
+}
+
+func TestLexiconText(t *testing.T) {
+	entries, err := loadLexicon(writeTestFile(t, "words.txt", "SHOR: 120\n\nJOD: 3\n"))
+	if err != nil {
+		t.Fatalf("failed to load the text lexicon: %v", err)
+	}
+	expected := "1:SHOR:120://:map[] 3:JOD:3://:map[]"
+	if actual := entriesString(entries); actual != expected {
+		t.Errorf("text lexicon: expected '%s', actual '%s'", expected, actual)
+	}
+
+	if _, err = loadLexicon(writeTestFile(t, "words.txt", "SHOR 120\n")); err == nil ||
+		!strings.Contains(err.Error(), "is not in the 'WORD: number' format") {
+		t.Errorf("text lexicon line with no colon: %v", err)
+	}
+	if _, err = loadLexicon(writeTestFile(t, "words.txt", "SHOR: many\n")); err == nil ||
+		!strings.Contains(err.Error(), "has a non numeric value 'many'") {
+		t.Errorf("text lexicon line with no number: %v", err)
+	}
+}
+
+func TestLexiconJSON(t *testing.T) {
+	entries, err := loadLexicon(writeTestFile(t, "words.json",
+		`[{"word": "SHOR", "freq": 120, "hebrew": "שור", "tags": ["animal", "farm"]},
+		  {"translit": "JOD", "frequency": 3, "c1": "J", "v": "O", "c2": "D", "pos": null}]`))
+	if err != nil {
+		t.Fatalf("failed to load the json lexicon: %v", err)
+	}
+	expected := "1:SHOR:120://:map[hebrew:שור tags:animal,farm] 2:JOD:3:J/O/D:map[]"
+	if actual := entriesString(entries); actual != expected {
+		t.Errorf("json lexicon: expected '%s', actual '%s'", expected, actual)
+	}
+
+	if _, err = loadLexicon(writeTestFile(t, "words.json", `[{"freq": 1}]`)); err == nil ||
+		!strings.Contains(err.Error(), "entry 1: entry has no word") {
+		t.Errorf("json lexicon entry with no word: %v", err)
+	}
+	if _, err = loadLexicon(writeTestFile(t, "words.json",
+		`[{"word": "SHOR", "freq": 1, "c1": "S", "v": "O", "c2": "R"}]`)); err == nil ||
+		!strings.Contains(err.Error(), "split c1/v/c2 'S/O/R' does not match") {
+		t.Errorf("json lexicon entry with a wrong split: %v", err)
+	}
+}
+
+func TestLexiconCSV(t *testing.T) {
+	entries, err := loadLexicon(writeTestFile(t, "words.csv",
+		"Word, Freq ,pos\n# a comment\nSHOR,120, noun\nJOD,3,\n"))
+	if err != nil {
+		t.Fatalf("failed to load the csv lexicon: %v", err)
+	}
+	expected := "3:SHOR:120://:map[pos:noun] 4:JOD:3://:map[]"
+	if actual := entriesString(entries); actual != expected {
+		t.Errorf("csv lexicon: expected '%s', actual '%s'", expected, actual)
+	}
+
+	if entries, err = loadLexicon(writeTestFile(t, "words.tsv", "word\tfreq\tipa\nSHOR\t120\tʃor\n")); err != nil {
+		t.Fatalf("failed to load the tsv lexicon: %v", err)
+	}
+	if actual := entriesString(entries); actual != "2:SHOR:120://:map[ipa:ʃor]" {
+		t.Errorf("tsv lexicon '%s'", actual)
+	}
+
+	if _, err = loadLexicon(writeTestFile(t, "words.csv", "word,freq\nSHOR,1.5\n")); err == nil ||
+		!strings.Contains(err.Error(), "words.csv:2: word 'SHOR' has a non numeric frequency '1.5'") {
+		t.Errorf("csv lexicon with a fraction frequency: %v", err)
+	}
+	if entries, err = loadLexicon(writeTestFile(t, "words.csv", "")); err != nil || len(entries) != 0 {
+		t.Errorf("empty csv lexicon loaded as %v, %v", entries, err)
+	}
+}
+
+func TestLexiconSplit(t *testing.T) {
+	vowels := map[string]int{"A": 1, "O": 1, "U": 1}
+	split := func(e lexEntry) string {
+		c1, v, c2, err := e.split(vowels)
+		if err != nil {
+			return err.Error()
+		}
+		return c1 + "/" + v + "/" + c2
+	}
+
+	if actual := split(lexEntry{word: "BOR"}); actual != "B/O/R" {
+		t.Errorf("BOR split as %s", actual)
+	}
+	if actual := split(lexEntry{word: "SHOR"}); actual != "SH/O/R" {
+		t.Errorf("SHOR split as %s", actual)
+	}
+	if actual := split(lexEntry{word: "BOTZ"}); actual != "B/O/TZ" {
+		t.Errorf("BOTZ split as %s", actual)
+	}
+	// the split given with the entry is taken as is
+	if actual := split(lexEntry{word: "SHOR", c1: "S", v: "HO", c2: "R"}); actual != "S/HO/R" {
+		t.Errorf("SHOR given split as %s", actual)
+	}
+	if _, _, _, err := (lexEntry{word: "BO"}).split(vowels); err == nil {
+		t.Errorf("BO with no coda split")
+	}
+}
+
+func TestLexiconScale(t *testing.T) {
+	// 1000 in total, so a count of 1 is 1000 per million
+	scaled := func(scale string) string {
+		entries := []lexEntry{{freq: 0}, {freq: 1}, {freq: 9}, {freq: 90}, {freq: 900}}
+		scaleFrequencies(entries, scale)
+		var freqs []int
+		for _, e := range entries {
+			freqs = append(freqs, e.freq)
+		}
+		return fmt.Sprint(freqs)
+	}
+
+	if actual := scaled("raw"); actual != "[0 1 9 90 900]" {
+		t.Errorf("raw frequencies %s", actual)
+	}
+	// the per-million and zipf scales are in hundredths
+	if actual := scaled("per-million"); actual != "[0 100000 900000 9000000 90000000]" {
+		t.Errorf("per-million frequencies %s", actual)
+	}
+	if actual := scaled("zipf"); actual != "[0 600 695 795 895]" {
+		t.Errorf("zipf frequencies %s", actual)
+	}
+	if actual := scaled("rank"); actual != "[0 25 50 75 100]" {
+		t.Errorf("rank frequencies %s", actual)
+	}
+
+	single := []lexEntry{{freq: 7}}
+	if scaleFrequencies(single, "rank"); single[0].freq != 100 {
+		t.Errorf("single word rank %d", single[0].freq)
+	}
+}
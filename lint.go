@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// lintProblem is a problem found in the words list
+type lintProblem struct {
+	line int
+	word string
+	msg  string
+}
+
+// lint report the problems of the words list (the lint command)
+func lint(args []string) error {
+	fname := GenVarOpts.InWordsFile
+	tables, err := loadAlphabet(&GenVarOpts.flagOpts)
+	if err != nil {
+		return err
+	}
+
+	var entries []lexEntry
+	var problems []lintProblem
+	switch strings.ToLower(filepath.Ext(fname)) {
+	case ".json", ".csv", ".tsv":
+		if entries, err = loadLexicon(fname); err != nil {
+			return err
+		}
+	default:
+		if entries, problems, err = lintTextLexicon(fname); err != nil {
+			return err
+		}
+	}
+	problems = append(problems, lintEntries(tables, entries)...)
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].line < problems[j].line
+	})
+	for _, p := range problems {
+		if p.word != "" {
+			fmt.Printf("%s:%d: %s: %s\n", fname, p.line, p.word, p.msg)
+		} else {
+			fmt.Printf("%s:%d: %s\n", fname, p.line, p.msg)
+		}
+	}
+	fmt.Printf("%d words, %d problems\n", len(entries), len(problems))
+	if len(problems) > 0 {
+		return fmt.Errorf("%s has problems", fname)
+	}
+	return nil
+}
+
+// lintTextLexicon read the "WORD: frequency" lines like loadTextLexicon but
+// report every malformed line instead of stopping at the first one
+func lintTextLexicon(fname string) ([]lexEntry, []lintProblem, error) {
+	lines, err := getLinesFromFile(fname)
+	if err != nil {
+		return nil, nil, err
+	}
+	var entries []lexEntry
+	var problems []lintProblem
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		word := strings.TrimSuffix(fields[0], ":")
+		switch {
+		case !strings.Contains(line, ":"):
+			problems = append(problems, lintProblem{i + 1, word, "missing colon after the word"})
+		case len(fields) != 2 || !strings.HasSuffix(fields[0], ":"):
+			problems = append(problems, lintProblem{i + 1, "",
+				fmt.Sprintf("'%s' is not in the 'WORD: frequency' format", line)})
+		default:
+			freq, err := strconv.Atoi(fields[1])
+			if err != nil {
+				problems = append(problems, lintProblem{i + 1, word,
+					fmt.Sprintf("frequency '%s' is not a number", fields[1])})
+				continue
+			}
+			entries = append(entries, lexEntry{line: i + 1, word: word, freq: freq})
+		}
+	}
+	return entries, problems, nil
+}
+
+// lintEntries report the duplicated words, the words which do not split
+// into phonemes of the alphabet or whose onset is their coda, and the
+// frequency outliers
+func lintEntries(tables *wordTables, entries []lexEntry) []lintProblem {
+	var problems []lintProblem
+	report := func(e lexEntry, f string, v ...interface{}) {
+		problems = append(problems, lintProblem{e.line, e.word, fmt.Sprintf(f, v...)})
+	}
+
+	first := make(map[string]int)
+	for _, e := range entries {
+		if line, ok := first[e.word]; ok {
+			report(e, "duplicate of line %d", line)
+		} else {
+			first[e.word] = e.line
+		}
+
+		c1, v, c2, err := e.split(tables.vowels)
+		if err != nil {
+			report(e, "%v", err)
+			continue
+		}
+		if _, ok := tables.consonants[c1]; !ok {
+			report(e, "onset '%s' is not in %s", c1, GenVarOpts.InConsonantFile)
+		}
+		if _, ok := tables.vowels[v]; !ok {
+			report(e, "vowel '%s' is not in %s", v, GenVarOpts.InVowelFile)
+		}
+		if _, ok := tables.consonants[c2]; !ok {
+			report(e, "coda '%s' is not in %s", c2, GenVarOpts.InConsonantFile)
+		}
+		if c1 == c2 {
+			report(e, "onset and coda are both '%s'", tables.showPhoneme(c1))
+		}
+		if e.freq <= 0 {
+			report(e, "frequency %d is not positive", e.freq)
+		}
+	}
+
+	// the frequencies spread over orders of magnitude, so the outliers are
+	// taken on their log
+	if GenLintOpts.Outlier <= 0 || len(entries) < 2 {
+		return problems
+	}
+	var sum, sumSq float64
+	var n int
+	for _, e := range entries {
+		if e.freq > 0 {
+			l := math.Log10(float64(e.freq))
+			sum += l
+			sumSq += l * l
+			n++
+		}
+	}
+	if n < 2 {
+		return problems
+	}
+	mean := sum / float64(n)
+	stddev := math.Sqrt(sumSq/float64(n) - mean*mean)
+	if stddev == 0 {
+		return problems
+	}
+	for _, e := range entries {
+		if e.freq <= 0 {
+			continue
+		}
+		if z := (math.Log10(float64(e.freq)) - mean) / stddev; math.Abs(z) > GenLintOpts.Outlier {
+			report(e, "frequency %d is an outlier (%.1f standard deviations from the mean)", e.freq, z)
+		}
+	}
+	return problems
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLintText(t *testing.T) {
+	entries, problems, err := lintTextLexicon(writeTestFile(t, "words.txt",
+		"BAD: 5\nGEK 9\n\nLIM: x\nNOP: 30 31\nRUT: 2\n"))
+	if err != nil {
+		t.Fatalf("failed to read the words list: %v", err)
+	}
+	if entriesString(entries) != "1:BAD:5://:map[] 6:RUT:2://:map[]" {
+		t.Errorf("words list entries '%s'", entriesString(entries))
+	}
+	expected := "[{2 GEK missing colon after the word} {4 LIM frequency 'x' is not a number}" +
+		" {5  'NOP: 30 31' is not in the 'WORD: frequency' format}]"
+	if actual := fmt.Sprint(problems); actual != expected {
+		t.Errorf("words list problems: expected '%s', actual '%s'", expected, actual)
+	}
+}
+
+func TestLint(t *testing.T) {
+	setTestWords(t, "BAD: 5\nGEK: 9\nBAD: 6\nBAB: 3\nZAG: 4\nLIM: 0\nNOP: 30\nRUT: 2\n")
+	GenLintOpts.Outlier = 3
+
+	out, err := captureOutput(t, func() error { return lint(nil) })
+	for _, line := range []string{
+		":3: BAD: duplicate of line 1\n",
+		":4: BAB: onset and coda are both 'B'\n",
+		":5: ZAG: onset 'Z' is not in ",
+		":6: LIM: frequency 0 is not positive\n",
+		"8 words, 4 problems\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("lint report is missing '%s': '%s'", line, out)
+		}
+	}
+	if err == nil || !strings.HasSuffix(err.Error(), "words_list.txt has problems") {
+		t.Errorf("lint error: %v", err)
+	}
+
+	// a frequency far from the others (on a log scale)
+	tables := &wordTables{vowels: map[string]int{"A": 1}, consonants: map[string]int{"B": 1, "D": 1, "G": 1}}
+	entries := []lexEntry{{line: 1, word: "BAD", freq: 100000}}
+	for i := 0; i < 20; i++ {
+		entries = append(entries, lexEntry{line: i + 2, word: "GAD", freq: 10 + i})
+	}
+	problems := lintEntries(tables, entries)
+	if len(problems) != 20 || !strings.Contains(problems[19].msg, "frequency 100000 is an outlier") {
+		t.Errorf("outlier problems %v", problems)
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gilwo/wordscvc/cvc"
+)
+
+// resultWriter stream the completed groups straight into the results file,
+// each flushed to disk as it is written, so groups found before a crash or
+// kill are kept on disk
+type resultWriter struct {
+	f *os.File
+}
+
+// newResultWriter create the results file, when appendResults is set the
+// groups are added at the end of an existing results file instead of
+// overwriting it
+func newResultWriter(fname string, appendResults bool) (*resultWriter, error) {
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if appendResults {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	f, err := os.OpenFile(fname, flag, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &resultWriter{f: f}, nil
+}
+
+// write add s to the results and flush it to disk
+func (rw *resultWriter) write(s string) error {
+	if _, err := rw.f.WriteString(s); err != nil {
+		return err
+	}
+	return rw.f.Sync()
+}
+
+// empty report whether nothing was written yet
+func (rw *resultWriter) empty() bool {
+	st, err := rw.f.Stat()
+	return err == nil && st.Size() == 0
+}
+
+// Close finish the results
+func (rw *resultWriter) Close() error {
+	return rw.f.Close()
+}
+
+// resultGroups return the index of the last group in the results file of
+// opts (0 when there is no such file or group), the groups appended to it
+// continue from there
+func resultGroups(opts *flagOpts) (int, error) {
+	data, err := ioutil.ReadFile(opts.OutResultFile)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	delim, _, err := csvOptions(opts)
+	if err != nil {
+		return 0, err
+	}
+	groups, err := parseGroups(string(data), opts.Format, delim)
+	if err != nil || len(groups) == 0 {
+		return 0, err
+	}
+	return groups[len(groups)-1].index, nil
+}
+
+// runParams are the generation parameters reported with each json group
+type runParams struct {
+	MaxGroups  int    `json:"max_groups"`
+	MaxSets    int    `json:"max_sets"`
+	MaxWords   int    `json:"max_words"`
+	FreqCutoff int    `json:"freq_cutoff"`
+	FreqAbove  int    `json:"freq_above"`
+	WordsFile  string `json:"words_file"`
+	FilterFile string `json:"filter_file,omitempty"`
+	SeedFile   string `json:"seed_file,omitempty"`
+	TimeToRun  int    `json:"time_to_run"`
+}
+
+func newRunParams(fo flagOpts) runParams {
+	return runParams{
+		MaxGroups:  fo.MaxGroups,
+		MaxSets:    fo.MaxSets,
+		MaxWords:   fo.MaxWords,
+		FreqCutoff: fo.FreqCutoff,
+		FreqAbove:  fo.FreqWordsPerLineAboveCutoff,
+		WordsFile:  fo.InWordsFile,
+		FilterFile: fo.FilterFile,
+		SeedFile:   fo.SeedFile,
+		TimeToRun:  fo.TimeToRun,
+	}
+}
+
+// csvColumns are the default csv/tsv header, one row per word placement
+var csvColumns = []string{
+	"group", "set", "position", "word", "onset", "vowel", "coda", "freq", "band"}
+
+// csvOptions return the delimiter and header (nil when omitted) of the
+// csv/tsv output formats
+func csvOptions(opts *flagOpts) (delim rune, header []string, err error) {
+	delim = '\t'
+	if opts.Format == "csv" && opts.Delimiter != "tab" {
+		r := []rune(opts.Delimiter)
+		if len(r) != 1 {
+			return 0, nil, fmt.Errorf("delimiter '%s' is not a single character",
+				opts.Delimiter)
+		}
+		delim = r[0]
+	}
+
+	columns := csvColumns
+	if opts.Hebrew {
+		columns = append(columns[:len(columns):len(columns)], "hebrew")
+	}
+	if opts.IPA {
+		columns = append(columns[:len(columns):len(columns)], "ipa")
+	}
+	switch opts.Header {
+	case "":
+		header = columns
+	case "none":
+	default:
+		header = strings.Split(opts.Header, ",")
+		if len(header) != len(columns) {
+			return 0, nil, fmt.Errorf("header '%s' should name %d columns (%s)",
+				opts.Header, len(columns), strings.Join(columns, ","))
+		}
+	}
+	return delim, header, nil
+}
+
+func formatCSV(opts *flagOpts, records [][]string) (string, error) {
+	delim, _, err := csvOptions(opts)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = delim
+	if err = w.WriteAll(records); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// formatHeader return the header written once ahead of the groups, if the
+// output format of opts has one
+func formatHeader(opts *flagOpts) (string, error) {
+	switch opts.Format {
+	case "csv", "tsv":
+		_, header, err := csvOptions(opts)
+		if err != nil || header == nil {
+			return "", err
+		}
+		return formatCSV(opts, [][]string{header})
+	case "html":
+		if opts.AppendResult {
+			return "", fmt.Errorf("html output can not be appended to")
+		}
+		return htmlHeader, nil
+	}
+	return "", nil
+}
+
+// formatFooter return the footer written after the last group, if the
+// output format of opts has one
+func formatFooter(opts *flagOpts) string {
+	if opts.Format == "html" {
+		return htmlFooter
+	}
+	return ""
+}
+
+// formatGroup render a completed group in the output format of opts, with
+// the words shown by the tables the group words were loaded with. json
+// groups are written one per line, csv/tsv one row per word placement
+func formatGroup(opts *flagOpts, tables *wordTables, index int, group *cvc.GroupSet) (string, error) {
+	switch opts.Format {
+	case "json":
+		data, err := json.Marshal(struct {
+			Index  int           `json:"index"`
+			Params runParams     `json:"params"`
+			Group  *cvc.GroupSet `json:"group"`
+		}{index, newRunParams(*opts), group})
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	case "csv", "tsv":
+		var records [][]string
+		for _, p := range group.Placements() {
+			rec := []string{
+				strconv.Itoa(index),
+				strconv.Itoa(p.Set),
+				strconv.Itoa(p.Position),
+				p.Word,
+				p.Onset,
+				p.Vowel,
+				p.Coda,
+				strconv.Itoa(p.Freq),
+				p.Band(),
+			}
+			if opts.Hebrew {
+				rec = append(rec, p.Meta["hebrew"])
+			}
+			if opts.IPA {
+				rec = append(rec, p.Meta["ipa"])
+			}
+			records = append(records, rec)
+		}
+		return formatCSV(opts, records)
+	case "html":
+		return formatHTML(opts, index, group)
+	default:
+		text := group.StringWithFreq()
+		if opts.Hebrew || opts.IPA {
+			text = formatWordsText(opts, tables, group)
+		}
+		msg := fmt.Sprintf("group completed\n%s\n", text)
+		if opts.DebugEnabled {
+			msg += group.DumpGroup() + "\n"
+		}
+		return msg, nil
+	}
+}
+
+// placementText return the placed word for the text outputs, with its
+// hebrew spelling and ipa transcription when asked
+func placementText(opts *flagOpts, tables *wordTables, p cvc.Placement) string {
+	w := p.Word
+	if hebrew, ok := p.Meta["hebrew"]; ok && opts.Hebrew {
+		w += "(" + hebrew + ")"
+	}
+	return tables.showWord(w, p.Meta["ipa"])
+}
+
+// formatWordsText render the group like GroupSet.StringWithFreq with the
+// hebrew spelling and ipa transcription next to each word, when asked
+func formatWordsText(opts *flagOpts, tables *wordTables, group *cvc.GroupSet) string {
+	var sets [][]string
+	for _, p := range group.Placements() {
+		if len(sets) < p.Set {
+			sets = append(sets, nil)
+		}
+		sets[p.Set-1] = append(sets[p.Set-1], fmt.Sprintf("%s:%d", placementText(opts, tables, p), p.Freq))
+	}
+
+	out := "\n"
+	for i, set := range sets {
+		out += fmt.Sprintf("\t%d:[%s]\n", i+1, strings.Join(set, ", "))
+	}
+	return out
+}
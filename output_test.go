@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gilwo/wordscvc/cvc"
+)
+
+func TestResultWriter(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "results.txt")
+
+	rw, err := newResultWriter(fname, false)
+	if err != nil {
+		t.Fatalf("failed to create the result writer: %v", err)
+	}
+	if !rw.empty() {
+		t.Errorf("new results are not empty")
+	}
+	if err = rw.write("group 1\n"); err != nil {
+		t.Fatalf("failed to write the results: %v", err)
+	}
+	// the groups are on disk as soon as written
+	if data, _ := ioutil.ReadFile(fname); string(data) != "group 1\n" {
+		t.Errorf("results before closing are '%s'", data)
+	}
+	if err = rw.Close(); err != nil {
+		t.Fatalf("failed to close the results: %v", err)
+	}
+
+	rw, err = newResultWriter(fname, true)
+	if err != nil {
+		t.Fatalf("failed to create the appending result writer: %v", err)
+	}
+	if rw.empty() {
+		t.Errorf("appended results are empty")
+	}
+	rw.write("group 2\n")
+	rw.Close()
+	if data, _ := ioutil.ReadFile(fname); string(data) != "group 1\ngroup 2\n" {
+		t.Errorf("appended results are '%s'", data)
+	}
+
+	rw, _ = newResultWriter(fname, false)
+	rw.write("group 3\n")
+	rw.Close()
+	if data, _ := ioutil.ReadFile(fname); string(data) != "group 3\n" {
+		t.Errorf("overwritten results are '%s'", data)
+	}
+}
+
+func TestResultGroups(t *testing.T) {
+	opts := flagOpts{}
+	opts.OutResultFile = filepath.Join(t.TempDir(), "results")
+	if first, err := resultGroups(&opts); err != nil || first != 0 {
+		t.Errorf("no results file has groups up to %d, %v", first, err)
+	}
+
+	for _, format := range []string{"text", "json", "csv"} {
+		opts.Format, opts.Delimiter = format, ";"
+		header, _ := formatHeader(&opts)
+		out1, _ := formatGroup(&opts, &wordTables{}, 1, testGroup())
+		out2, _ := formatGroup(&opts, &wordTables{}, 2, testGroup())
+		if err := ioutil.WriteFile(opts.OutResultFile, []byte(header+out1+out2), 0644); err != nil {
+			t.Fatalf("failed to write the %s results: %v", format, err)
+		}
+		if first, err := resultGroups(&opts); err != nil || first != 2 {
+			t.Errorf("%s results have groups up to %d, %v", format, first, err)
+		}
+	}
+}
+
+// testGroup return a group of one set of two words, SHOR with its hebrew
+// spelling and ipa
+func testGroup() *cvc.GroupSet {
+	group := cvc.NewGroupSetLimitFreq(1, 2, 20, 1)
+	group.AddWord(cvc.NewWordMeta("SH", "O", "R", 75, map[string]string{"hebrew": "שור", "ipa": "ʃoʁ"}))
+	group.AddWord(cvc.NewWord("J", "A", "D", 2))
+	return group
+}
+
+func TestOutputJSON(t *testing.T) {
+	opts := flagOpts{}
+	opts.Format, opts.MaxGroups, opts.InWordsFile = "json", 4, "words.txt"
+	out, err := formatGroup(&opts, &wordTables{}, 3, testGroup())
+	if err != nil {
+		t.Fatalf("failed to format the json group: %v", err)
+	}
+	if !strings.HasSuffix(out, "}\n") || strings.Count(out, "\n") != 1 {
+		t.Errorf("json group is not a single line '%s'", out)
+	}
+
+	var line struct {
+		Index  int
+		Params runParams
+		Group  struct {
+			Sets []struct {
+				Words []struct{ Word string }
+			}
+		}
+	}
+	if err = json.Unmarshal([]byte(out), &line); err != nil {
+		t.Fatalf("failed to decode the json group '%s': %v", out, err)
+	}
+	if line.Index != 3 || line.Params.MaxGroups != 4 || line.Params.WordsFile != "words.txt" {
+		t.Errorf("json group index %d params %+v", line.Index, line.Params)
+	}
+	if len(line.Group.Sets) != 1 || len(line.Group.Sets[0].Words) != 2 ||
+		line.Group.Sets[0].Words[0].Word != "SHOR" {
+		t.Errorf("json group sets %+v", line.Group.Sets)
+	}
+}
+
+func TestOutputCSVHeader(t *testing.T) {
+	opts := flagOpts{}
+	opts.Format, opts.Delimiter = "csv", ","
+	header, err := formatHeader(&opts)
+	if err != nil || header != "group,set,position,word,onset,vowel,coda,freq,band\n" {
+		t.Errorf("csv header '%s', %v", header, err)
+	}
+
+	opts.Delimiter = "tab"
+	if header, _ = formatHeader(&opts); header != "group\tset\tposition\tword\tonset\tvowel\tcoda\tfreq\tband\n" {
+		t.Errorf("csv header with a tab delimiter '%s'", header)
+	}
+	opts.Format, opts.Delimiter = "tsv", ";"
+	if header, _ = formatHeader(&opts); !strings.HasPrefix(header, "group\tset\t") {
+		t.Errorf("tsv header '%s'", header)
+	}
+
+	opts.Format, opts.Delimiter, opts.Header = "csv", ";", "g,s,p,w,c1,v,c2,f,b"
+	if header, _ = formatHeader(&opts); header != "g;s;p;w;c1;v;c2;f;b\n" {
+		t.Errorf("renamed csv header '%s'", header)
+	}
+	opts.Header = "none"
+	if header, err = formatHeader(&opts); err != nil || header != "" {
+		t.Errorf("omitted csv header '%s', %v", header, err)
+	}
+
+	opts.Header = "a,b"
+	if _, err = formatHeader(&opts); err == nil ||
+		!strings.Contains(err.Error(), "header 'a,b' should name 9 columns") {
+		t.Errorf("csv header with missing columns: %v", err)
+	}
+	opts.Header, opts.Delimiter = "", ";;"
+	if _, err = formatHeader(&opts); err == nil ||
+		err.Error() != "delimiter ';;' is not a single character" {
+		t.Errorf("csv long delimiter: %v", err)
+	}
+}
+
+func TestOutputCSVGroup(t *testing.T) {
+	opts := flagOpts{}
+	opts.Format, opts.Delimiter = "csv", ","
+	out, err := formatGroup(&opts, &wordTables{}, 3, testGroup())
+	if err != nil {
+		t.Fatalf("failed to format the csv group: %v", err)
+	}
+	expected := "3,1,1,SHOR,SH,O,R,75,above\n3,1,2,JAD,J,A,D,2,below\n"
+	if out != expected {
+		t.Errorf("csv group: expected '%s', actual '%s'", expected, out)
+	}
+
+	opts.Hebrew = true
+	if out, _ = formatGroup(&opts, &wordTables{}, 3, testGroup()); out !=
+		"3,1,1,SHOR,SH,O,R,75,above,שור\n3,1,2,JAD,J,A,D,2,below,\n" {
+		t.Errorf("csv group with the hebrew column '%s'", out)
+	}
+}
+
+func TestOutputIPA(t *testing.T) {
+	opts := flagOpts{}
+	opts.Format, opts.Delimiter, opts.Hebrew = "csv", ",", true
+	opts.IPA = true
+	out, err := formatGroup(&opts, &wordTables{}, 3, testGroup())
+	if err != nil {
+		t.Fatalf("failed to format the csv group: %v", err)
+	}
+	if out != "3,1,1,SHOR,SH,O,R,75,above,שור,ʃoʁ\n3,1,2,JAD,J,A,D,2,below,,\n" {
+		t.Errorf("csv group with the ipa column '%s'", out)
+	}
+
+	opts.Header = "g,s,p,w,c1,v,c2,f,b,heb"
+	if _, err = formatHeader(&opts); err == nil || !strings.Contains(err.Error(),
+		"should name 11 columns (group,set,position,word,onset,vowel,coda,freq,band,hebrew,ipa)") {
+		t.Errorf("csv header without the ipa column: %v", err)
+	}
+
+	opts.Format = "text"
+	tables := &wordTables{showIPA: true}
+	if out, _ = formatGroup(&opts, tables, 1, testGroup()); out !=
+		"group completed\n\n\t1:[SHOR(שור) /ʃoʁ/:75, JAD:2]\n\n" {
+		t.Errorf("text group with the ipa '%s'", out)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/jessevdk/go-flags"
+)
+
+// language profile manifest, bundle the alphabet, lexicon, transliteration
+// and default rule parameters of a language:
+//
+//	{
+//	  "name": "hebrew",
+//	  "consonants": "consonants.txt",
+//	  "vowels": "vowels.txt",
+//	  "words": "words_list.txt",
+//	  "translit": "hebrew.txt",
+//	  "max_sets": 15,
+//	  "max_words": 10,
+//	  "freq_cutoff": 25,
+//	  "freq_above": 3,
+//	  "freq_scale": "raw"
+//	}
+//
+// the files are relative to the manifest directory, all the fields are
+// optional and the command line options override them
+
+const profileManifest = "profile.json"
+
+type languageProfile struct {
+	Name       string `json:"name"`
+	Consonants string `json:"consonants"`
+	Vowels     string `json:"vowels"`
+	Words      string `json:"words"`
+	Filter     string `json:"filter"`
+	Translit   string `json:"translit"`
+	MaxSets    int    `json:"max_sets"`
+	MaxWords   int    `json:"max_words"`
+	FreqCutoff int    `json:"freq_cutoff"`
+	FreqAbove  int    `json:"freq_above"`
+	FreqScale  string `json:"freq_scale"`
+}
+
+// loadProfile read the profile manifest, name is the manifest file or a
+// directory holding profile.json
+func loadProfile(name string) (*languageProfile, error) {
+	if st, err := os.Stat(name); err == nil && st.IsDir() {
+		name = filepath.Join(name, profileManifest)
+	}
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	prof := &languageProfile{}
+	if err = json.Unmarshal(data, prof); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	dir := filepath.Dir(name)
+	for _, f := range []*string{
+		&prof.Consonants, &prof.Vowels, &prof.Words, &prof.Filter, &prof.Translit} {
+		if *f != "" && !filepath.IsAbs(*f) {
+			*f = filepath.Join(dir, *f)
+		}
+	}
+	return prof, nil
+}
+
+// defaults return the profile values by option name
+func (prof *languageProfile) defaults() map[string][]string {
+	values := make(map[string][]string)
+	for name, f := range map[string]string{
+		"C": prof.Consonants, "V": prof.Vowels, "i": prof.Words,
+		"F": prof.Filter, "translit": prof.Translit, "freq-scale": prof.FreqScale} {
+		if f != "" {
+			values[name] = []string{f}
+		}
+	}
+	for name, n := range map[string]int{
+		"S": prof.MaxSets, "W": prof.MaxWords,
+		"f": prof.FreqCutoff, "a": prof.FreqAbove} {
+		if n != 0 {
+			values[name] = []string{strconv.Itoa(n)}
+		}
+	}
+	return values
+}
+
+// setDefaults replace the default value of the parser options and of its
+// commands options (each command has its own copy of the shared options),
+// values are keyed by the option short or long name
+func setDefaults(parser *flags.Parser, values map[string][]string) error {
+	for name, value := range values {
+		found := false
+		for _, c := range append([]*flags.Command{parser.Command}, parser.Commands()...) {
+			var opt *flags.Option
+			if len(name) == 1 {
+				opt = c.FindOptionByShortName(rune(name[0]))
+			} else {
+				opt = c.FindOptionByLongName(name)
+			}
+			if opt != nil {
+				opt.Default = value
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown option '%s'", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jessevdk/go-flags"
+)
+
+func TestProfileLoad(t *testing.T) {
+	manifest := writeTestFile(t, "profile.json", `{"name": "test", "consonants": "c.txt",
+		"words": "/data/words.txt", "translit": "hebrew.txt", "max_sets": 12, "freq_scale": "zipf"}`)
+	dir := filepath.Dir(manifest)
+
+	// a profile directory stand for its profile.json
+	prof, err := loadProfile(dir)
+	if err != nil {
+		t.Fatalf("failed to load the profile: %v", err)
+	}
+	if prof.Name != "test" || prof.MaxSets != 12 {
+		t.Errorf("profile loaded as %+v", prof)
+	}
+	if prof.Consonants != filepath.Join(dir, "c.txt") || prof.Words != "/data/words.txt" {
+		t.Errorf("profile files are not relative to its directory: %s %s", prof.Consonants, prof.Words)
+	}
+
+	expected := fmt.Sprintf("map[C:[%s] S:[12] freq-scale:[zipf] i:[/data/words.txt] translit:[%s]]",
+		filepath.Join(dir, "c.txt"), filepath.Join(dir, "hebrew.txt"))
+	if actual := fmt.Sprint(prof.defaults()); actual != expected {
+		t.Errorf("profile defaults: expected '%s', actual '%s'", expected, actual)
+	}
+
+	if _, err = loadProfile(writeTestFile(t, "profile.json", `{"max_sets": "many"}`)); err == nil ||
+		!strings.Contains(err.Error(), "profile.json: json: cannot unmarshal string") {
+		t.Errorf("profile with a bad value: %v", err)
+	}
+}
+
+func TestProfileHebrew(t *testing.T) {
+	prof, err := loadProfile("hebrew.profile.json")
+	if err != nil {
+		t.Fatalf("failed to load the hebrew profile: %v", err)
+	}
+	if prof.Translit != "hebrew.txt" || prof.MaxSets != 15 || prof.MaxWords != 10 {
+		t.Errorf("hebrew profile loaded as %+v", prof)
+	}
+}
+
+// the profile values replace the options defaults, the command line
+// options override them
+func TestProfileDefaults(t *testing.T) {
+	var opts struct {
+		MaxSets int    `short:"S" default:"15"`
+		Words   string `short:"i" default:"words_list.txt"`
+	}
+	parser := flags.NewParser(&opts, flags.Default)
+	if err := setDefaults(parser, map[string][]string{"S": {"12"}, "i": {"w.txt"}}); err != nil {
+		t.Fatalf("failed to set the defaults: %v", err)
+	}
+
+	if _, err := parser.ParseArgs(nil); err != nil || opts.MaxSets != 12 || opts.Words != "w.txt" {
+		t.Errorf("profile defaults parsed as %+v, %v", opts, err)
+	}
+	if _, err := parser.ParseArgs([]string{"-S", "4"}); err != nil || opts.MaxSets != 4 || opts.Words != "w.txt" {
+		t.Errorf("command line over the profile defaults parsed as %+v, %v", opts, err)
+	}
+
+	if err := setDefaults(parser, map[string][]string{"colour": {"red"}}); err == nil ||
+		err.Error() != "unknown option 'colour'" {
+		t.Errorf("default of an unknown option: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gilwo/wordscvc/cvc"
+)
+
+// query list the words matching all the query c1/v/c2 patterns, and one of
+// the query words when given (the query command)
+func query(args []string) error {
+	var rules []*filterRule
+	var words []string
+	for _, pattern := range append(GenQueryOpts.Args.Patterns, args...) {
+		if !strings.Contains(pattern, "=") {
+			words = append(words, pattern)
+			continue
+		}
+		r, err := newFilterRule(0, "include "+pattern)
+		if err != nil {
+			return fmt.Errorf("pattern '%s': %v", pattern, err)
+		}
+		rules = append(rules, r)
+	}
+	if len(words) > 0 {
+		r, err := newFilterRule(0, "include "+strings.Join(words, " "))
+		if err != nil {
+			return fmt.Errorf("words %s: %v", strings.Join(words, " "), err)
+		}
+		rules = append(rules, r)
+	}
+
+	wmap, tables, err := loadWords(&GenVarOpts.flagOpts)
+	if err != nil {
+		return err
+	}
+
+	var found []cvc.Placement
+	for _, w := range wmap.Words() {
+		p := cvc.WordPlacement(w, GenVarOpts.FreqCutoff)
+
+		match := true
+		for _, r := range rules {
+			match = match && r.match(p.Onset, p.Vowel, p.Coda)
+		}
+		if match {
+			found = append(found, p)
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if GenQueryOpts.Sort == "freq" && found[i].Freq != found[j].Freq {
+			return found[i].Freq > found[j].Freq
+		}
+		return found[i].Word < found[j].Word
+	})
+	if GenQueryOpts.Limit > 0 && len(found) > GenQueryOpts.Limit {
+		found = found[:GenQueryOpts.Limit]
+	}
+
+	for _, p := range found {
+		fmt.Printf("%s:%d %s\n", placementText(&GenVarOpts.flagOpts, tables, p), p.Freq, p.Band())
+	}
+	fmt.Printf("%d words\n", len(found))
+	return nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	setTestWords(t, testWordsList)
+
+	GenQueryOpts.Args.Patterns = []string{"v=A,I"}
+	out, err := captureOutput(t, func() error { return query(nil) })
+	expected := "BAD:5 below\nDAB:7 below\nLIM:1 below\nMIL:40 above\n4 words\n"
+	if err != nil || out != expected {
+		t.Errorf("query v=A,I: expected '%s', actual '%s', %v", expected, out, err)
+	}
+
+	GenQueryOpts.Sort, GenQueryOpts.Limit = "freq", 2
+	out, _ = captureOutput(t, func() error { return query(nil) })
+	if expected = "MIL:40 above\nDAB:7 below\n2 words\n"; out != expected {
+		t.Errorf("query v=A,I by frequency: expected '%s', actual '%s'", expected, out)
+	}
+
+	// the words and the patterns must all match
+	GenQueryOpts.Args.Patterns = []string{"c1=N,P"}
+	out, _ = captureOutput(t, func() error { return query([]string{"NOP", "MIL"}) })
+	if expected = "NOP:30 above\n1 words\n"; out != expected {
+		t.Errorf("query NOP MIL c1=N,P: expected '%s', actual '%s'", expected, out)
+	}
+
+	GenQueryOpts.Args.Patterns = []string{"c3=B"}
+	if _, err = captureOutput(t, func() error { return query(nil) }); err == nil {
+		t.Errorf("query with an unknown role")
+	}
+}
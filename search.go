@@ -0,0 +1,230 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gilwo/wordscvc/cvc"
+	"github.com/gilwo/workqueue/pool"
+)
+
+// search is one run of the groups search, its state is shared by the
+// findGroups workers of the run
+type search struct {
+	nodes int64 // workers started (search nodes explored), updated atomically
+
+	opts flagOpts
+
+	// found is called with each completed group and its index (one based),
+	// depth with each new maximal depth (words placed) reached, both from
+	// the collecting goroutine
+	found func(index int, group *cvc.GroupSet)
+	depth func(size int)
+
+	waitForWorkers chan bool
+	collectingDone chan struct{}
+	msgs           chan string
+	groupsFound    chan *cvc.GroupSet
+	startedWorkers chan struct{}
+	stoppedWorkers chan struct{}
+	disposeChan    chan *workerpool.WorkerJob
+	disposeDone    chan bool
+	// quit is closed with the finish signal, the workers (and the job
+	// disposer) blocked sending give up then, the channels they send to are
+	// never closed
+	quit chan struct{}
+
+	// shared by the workers and the run goroutines, updated atomically
+	countGroups    int64
+	finishSignal   int32
+	maxSize        int64
+	maxWorkers     int64
+	currentWorkers int64
+}
+
+// explored return the number of search nodes explored so far
+func (s *search) explored() int64 {
+	return atomic.LoadInt64(&s.nodes)
+}
+
+// finished return true once the search is finishing, see finish
+func (s *search) finished() bool {
+	return atomic.LoadInt32(&s.finishSignal) == 1
+}
+
+// finish issue the finish signal, the workers stop exploring
+func (s *search) finish() {
+	atomic.StoreInt32(&s.finishSignal, 1)
+	close(s.quit)
+}
+
+// send send m to the collector, unless the search is finishing
+func (s *search) send(m string) {
+	select {
+	case s.msgs <- m:
+	case <-s.quit:
+	}
+}
+
+func newSearch(opts flagOpts) *search {
+	return &search{
+		opts:           opts,
+		waitForWorkers: make(chan bool),
+		collectingDone: make(chan struct{}),
+		msgs:           make(chan string, 100),
+		groupsFound:    make(chan *cvc.GroupSet, 100),
+		startedWorkers: make(chan struct{}, 100),
+		stoppedWorkers: make(chan struct{}, 100),
+		disposeChan:    make(chan *workerpool.WorkerJob, 1000),
+		disposeDone:    make(chan bool),
+		quit:           make(chan struct{}),
+	}
+}
+
+// run search for groups completing group with the words of wmap, until the
+// required groups are found, the time to run passed or stop is closed, and
+// return the run time once all the workers stopped
+func (s *search) run(group *cvc.GroupSet, wmap *cvc.WordMap, stop <-chan struct{}) time.Duration {
+	// start time measuring
+	t0 := time.Now()
+
+	if s.opts.UsePool && s.opts.UseJobDispose {
+		// job disposer
+		go func() {
+			defer close(s.disposeDone)
+			for {
+				select {
+				case j := <-s.disposeChan:
+					if j.JobStatus() == workerpool.Jfinished {
+						j.JobDispose()
+						continue
+					}
+					select {
+					case s.disposeChan <- j:
+					case <-s.quit:
+						return
+					}
+				case <-s.quit:
+					return
+				}
+			}
+		}()
+	}
+
+	// wait for all goroutines to finish
+	go func() {
+		count := 0
+		i := 0
+		for {
+			s.opts.verbose("going to wait\n")
+			select {
+			case <-s.startedWorkers:
+				s.opts.trace("startedWorkers")
+				atomic.AddInt64(&s.nodes, 1)
+				count++
+				if int64(count) > atomic.LoadInt64(&s.maxWorkers) {
+					atomic.StoreInt64(&s.maxWorkers, int64(count))
+				}
+				atomic.StoreInt64(&s.currentWorkers, int64(count))
+				i = 0
+			case <-s.stoppedWorkers:
+				count--
+				atomic.StoreInt64(&s.currentWorkers, int64(count))
+				i = 0
+			case <-time.After(1 * time.Second):
+				if s.opts.UsePool {
+					s.opts.trace("%v\n", pool.PoolStats())
+				}
+				if count > 0 {
+					s.opts.info("there are still %d active workers\n", count)
+					s.opts.trace("count = %d and i = %d", count, i)
+				} else {
+					s.opts.verbose("count = 0 and i = %d\n", i)
+					i++
+					if i == 3 {
+						s.waitForWorkers <- true
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	// msg collector
+	go func() {
+		for {
+			select {
+			case m := <-s.msgs:
+				if strings.HasPrefix(m, "depth: ") {
+					size, _ := strconv.Atoi(m[len("depth: "):])
+					if int64(size) > atomic.LoadInt64(&s.maxSize) {
+						atomic.StoreInt64(&s.maxSize, int64(size))
+						s.opts.verbose("max depth : %d\n", size)
+						if s.depth != nil {
+							s.depth(size)
+						}
+					}
+				} else if strings.HasPrefix(m, "status:") {
+					s.opts.info("%s", m)
+				}
+			case g := <-s.groupsFound:
+				count := int(atomic.AddInt64(&s.countGroups, 1))
+				if s.found != nil {
+					s.found(count, g)
+				}
+				if count == s.opts.MaxGroups {
+					close(s.collectingDone)
+					return
+				}
+			default:
+				time.Sleep(1 * time.Second)
+				s.opts.verbose("%s passed\n", time.Now().Sub(t0))
+				if s.opts.UsePool {
+					s.opts.info("%v\n", pool.PoolStats())
+				}
+				if s.finished() {
+					s.opts.info("finishSignal issued, exiting")
+					return
+				}
+				s.opts.debug("current workers %d, max workers %d\n",
+					atomic.LoadInt64(&s.currentWorkers), atomic.LoadInt64(&s.maxWorkers))
+			}
+		}
+	}()
+
+	if s.opts.UsePool {
+		pool.NewJobQueue(s.findGroups, findArg{group, wmap})
+	} else {
+		go s.findGroups(findArg{group, wmap}, nil, nil)
+	}
+
+	dur := time.Duration(s.opts.TimeToRun)
+	select {
+	case <-s.collectingDone:
+		s.opts.info("required results collected after %s\n", time.Now().Sub(t0))
+	case <-time.After(dur * time.Second):
+		s.opts.info("stopped after %s\n", time.Now().Sub(t0))
+	case <-stop:
+		s.opts.info("stopped after %s\n", time.Now().Sub(t0))
+	}
+	s.finish()
+
+	// pool cleanup
+	if s.opts.UsePool {
+		ch := make(chan struct{})
+		pool.StopDispatcher(func() {
+			select {
+			case <-s.disposeDone:
+			}
+			close(ch)
+		})
+		pool.Dispose()
+		<-ch
+	}
+
+	s.opts.info("waiting for waitForWorkers, %d workers\n", atomic.LoadInt64(&s.currentWorkers))
+	<-s.waitForWorkers
+	return time.Now().Sub(t0)
+}
@@ -0,0 +1,443 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gilwo/wordscvc/cvc"
+)
+
+// http api, the jobs are kept in memory:
+//
+//	POST   /jobs              submit a job, the body is a json object of the
+//	                          job options (see jobKeys), keyed like the
+//	                          configuration file
+//	GET    /jobs              list the jobs status
+//	GET    /jobs/ID           the job status and progress
+//	GET    /jobs/ID/results   the groups found so far, ?format= any output
+//	                          format (text by default)
+//	GET    /jobs/ID/events    server sent events stream of the job status,
+//	                          a "progress" event each second and an "end"
+//	                          event once the job is over
+//	DELETE /jobs/ID           cancel the job, the groups found are kept
+//
+// the server keeps the --keep-jobs last finished jobs, the older ones are
+// dropped (with their results) as new jobs are submitted
+//
+// errors are answered with a json object: {"error": "..."}
+
+// jobKeys are the options a job can set, the other options are the server
+// ones
+var jobKeys = []string{
+	"max_groups", "max_sets", "max_words", "freq_cutoff", "freq_above", "freq_scale",
+	"consonants", "vowels", "words", "filter", "translit", "seed",
+	"niqqud", "hebrew", "ipa", "delimiter", "header", "html_freq", "html_marks",
+	"time_to_run",
+}
+
+// jobFileKeys are the job options naming a file of the data directory
+var jobFileKeys = []string{"consonants", "vowels", "words", "filter", "translit", "seed"}
+
+// jobStatus is the job state and progress reported by the api
+type jobStatus struct {
+	ID        string     `json:"id"`
+	State     string     `json:"state"` // queued, running, cancelling, done, cancelled or failed
+	Error     string     `json:"error,omitempty"`
+	Groups    int        `json:"groups"`
+	MaxGroups int        `json:"max_groups"`
+	Depth     int        `json:"depth"` // most words placed in a group so far
+	MaxDepth  int        `json:"max_depth"`
+	Nodes     int64      `json:"nodes"`   // search nodes explored
+	Elapsed   float64    `json:"elapsed"` // seconds running
+	TimeToRun int        `json:"time_to_run"`
+	Created   time.Time  `json:"created"`
+	Started   *time.Time `json:"started,omitempty"`
+	Finished  *time.Time `json:"finished,omitempty"`
+}
+
+type job struct {
+	mu     sync.Mutex
+	status jobStatus
+	opts   flagOpts
+	tables *wordTables // the tables the job words were loaded with
+	groups []*cvc.GroupSet
+	search *search
+	stop   chan struct{}
+	cancel sync.Once
+	done   chan struct{}
+}
+
+type server struct {
+	mu    sync.Mutex
+	jobs  map[string]*job
+	next  int
+	slots chan struct{}
+	keep  int // finished jobs kept
+}
+
+// serve run the http api (the serve command)
+func serve(args []string) error {
+	if GenServeOpts.Jobs < 1 {
+		return fmt.Errorf("jobs limit %d should be at least 1", GenServeOpts.Jobs)
+	}
+	if GenServeOpts.KeepJobs < 1 {
+		return fmt.Errorf("finished jobs kept %d should be at least 1", GenServeOpts.KeepJobs)
+	}
+	srv := &server{
+		jobs:  make(map[string]*job),
+		slots: make(chan struct{}, GenServeOpts.Jobs),
+		keep:  GenServeOpts.KeepJobs,
+	}
+	http.HandleFunc("/jobs", srv.handleJobs)
+	http.HandleFunc("/jobs/", srv.handleJob)
+
+	fmt.Printf("serving the api on %s, %d jobs at a time\n", GenServeOpts.Addr, GenServeOpts.Jobs)
+	return http.ListenAndServe(GenServeOpts.Addr, nil)
+}
+
+func (srv *server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		srv.mu.Lock()
+		list := []jobStatus{}
+		for _, j := range srv.jobs {
+			list = append(list, j.snapshot())
+		}
+		srv.mu.Unlock()
+		sort.Slice(list, func(a, b int) bool { return list[a].Created.Before(list[b].Created) })
+		writeJSON(w, http.StatusOK, list)
+	case http.MethodPost:
+		opts, err := jobOpts(http.MaxBytesReader(w, r.Body, 1<<20))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		j := srv.submit(opts)
+		w.Header().Set("Location", "/jobs/"+j.status.ID)
+		writeJSON(w, http.StatusCreated, j.snapshot())
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+func (srv *server) handleJob(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/jobs/"), "/")
+	srv.mu.Lock()
+	j, ok := srv.jobs[parts[0]]
+	srv.mu.Unlock()
+	if !ok || len(parts) > 2 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no job at %s", r.URL.Path))
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, j.snapshot())
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		j.cancel.Do(func() { close(j.stop) })
+		j.update(func(st *jobStatus) {
+			// the workers take a while to stop
+			if st.State == "running" {
+				st.State = "cancelling"
+			}
+		})
+		writeJSON(w, http.StatusAccepted, j.snapshot())
+	case parts[1] == "events" && r.Method == http.MethodGet:
+		j.streamEvents(w, r)
+	case parts[1] == "results" && r.Method == http.MethodGet:
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "text"
+		}
+		body, err := j.results(format)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		w.Header().Set("Content-Type", formatContentType[format])
+		io.WriteString(w, body)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("no %s %s", r.Method, r.URL.Path))
+	}
+}
+
+// jobOpts read the job options over the server options
+func jobOpts(body io.Reader) (flagOpts, error) {
+	opts := GenVarOpts.flagOpts
+	opts.OutResultFile, opts.AppendResult = "", false
+	opts.CpuProfile, opts.MemProfile = "", ""
+	opts.UsePool = false
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return opts, err
+	}
+	var raw map[string]json.RawMessage
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return opts, err
+	}
+	for key := range raw {
+		if !inList(key, jobKeys) {
+			return opts, fmt.Errorf("option '%s' can not be set by a job", key)
+		}
+	}
+	if err = json.Unmarshal(data, &opts); err != nil {
+		return opts, err
+	}
+
+	files := map[string]*string{
+		"consonants": &opts.InConsonantFile, "vowels": &opts.InVowelFile,
+		"words": &opts.InWordsFile, "filter": &opts.FilterFile,
+		"translit": &opts.TranslitFile, "seed": &opts.SeedFile}
+	for _, key := range jobFileKeys {
+		f := files[key]
+		if _, ok := raw[key]; !ok || *f == "" {
+			continue
+		}
+		if filepath.IsAbs(*f) || strings.HasPrefix(filepath.Clean(*f), "..") {
+			return opts, fmt.Errorf("%s '%s' is not a file of the data directory", key, *f)
+		}
+		*f = filepath.Join(GenServeOpts.DataDir, *f)
+	}
+
+	switch {
+	case opts.MaxGroups < 1 || opts.MaxSets < 1 || opts.MaxWords < 1:
+		return opts, fmt.Errorf("max_groups, max_sets and max_words should be at least 1")
+	case opts.FreqWordsPerLineAboveCutoff < 0 || opts.FreqWordsPerLineAboveCutoff > opts.MaxWords:
+		return opts, fmt.Errorf("freq_above should be between 0 and max_words")
+	case opts.TimeToRun < 1 || opts.TimeToRun > GenServeOpts.MaxTime:
+		return opts, fmt.Errorf("time_to_run should be between 1 and %d", GenServeOpts.MaxTime)
+	case !inList(opts.FreqScale, []string{"raw", "per-million", "zipf", "rank"}):
+		return opts, fmt.Errorf("unknown freq_scale '%s'", opts.FreqScale)
+	}
+	return opts, nil
+}
+
+// submit queue a job, it runs once one of the server slots is free
+func (srv *server) submit(opts flagOpts) *job {
+	srv.mu.Lock()
+	srv.evict()
+	srv.next++
+	j := &job{
+		status: jobStatus{
+			ID:        strconv.Itoa(srv.next),
+			State:     "queued",
+			MaxGroups: opts.MaxGroups,
+			MaxDepth:  opts.MaxSets * opts.MaxWords,
+			TimeToRun: opts.TimeToRun,
+			Created:   time.Now(),
+		},
+		opts: opts,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	srv.jobs[j.status.ID] = j
+	srv.mu.Unlock()
+
+	go srv.run(j)
+	return j
+}
+
+// evict drop the oldest finished jobs beyond the kept ones, srv.mu is held
+func (srv *server) evict() {
+	var finished []jobStatus
+	for _, j := range srv.jobs {
+		if st := j.snapshot(); st.Finished != nil {
+			finished = append(finished, st)
+		}
+	}
+	if len(finished) <= srv.keep {
+		return
+	}
+	sort.Slice(finished, func(a, b int) bool { return finished[a].Finished.Before(*finished[b].Finished) })
+	for _, st := range finished[:len(finished)-srv.keep] {
+		delete(srv.jobs, st.ID)
+	}
+}
+
+func (srv *server) run(j *job) {
+	select {
+	case srv.slots <- struct{}{}:
+		defer func() { <-srv.slots }()
+	case <-j.stop:
+		j.finish("cancelled", nil)
+		return
+	}
+	j.update(func(st *jobStatus) {
+		now := time.Now()
+		st.State, st.Started = "running", &now
+	})
+
+	group, wmap, tables, err := loadJob(&j.opts)
+	if err != nil {
+		j.finish("failed", err)
+		return
+	}
+
+	s := newSearch(j.opts)
+	j.mu.Lock()
+	j.search, j.tables = s, tables
+	j.mu.Unlock()
+	s.found = func(index int, g *cvc.GroupSet) {
+		j.mu.Lock()
+		j.groups = append(j.groups, g)
+		j.status.Groups = index
+		j.mu.Unlock()
+	}
+	s.depth = func(size int) {
+		j.update(func(st *jobStatus) { st.Depth = size })
+	}
+	s.run(group, wmap, j.stop)
+
+	select {
+	case <-j.stop:
+		j.finish("cancelled", nil)
+	default:
+		j.finish("done", nil)
+	}
+}
+
+// loadJob load the words, their tables and the base group of the job opts,
+// a panic while loading fails the job rather than the server
+func loadJob(opts *flagOpts) (group *cvc.GroupSet, wmap *cvc.WordMap, tables *wordTables, err error) {
+	defer func() {
+		if fail := recover(); fail != nil {
+			err = fmt.Errorf("%v", fail)
+		}
+	}()
+	if wmap, tables, err = loadWords(opts); err != nil {
+		return nil, nil, nil, err
+	}
+	if group, err = newBaseGroup(opts, tables, wmap); err != nil {
+		return nil, nil, nil, err
+	}
+	return group, wmap, tables, nil
+}
+
+func (j *job) update(f func(*jobStatus)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	f(&j.status)
+}
+
+func (j *job) finish(state string, err error) {
+	j.update(func(st *jobStatus) {
+		now := time.Now()
+		st.State, st.Finished = state, &now
+		if err != nil {
+			st.Error = err.Error()
+		}
+	})
+	close(j.done)
+}
+
+func (j *job) snapshot() jobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	st := j.status
+	if j.search != nil {
+		st.Nodes = j.search.explored()
+	}
+	if st.Started != nil {
+		end := time.Now()
+		if st.Finished != nil {
+			end = *st.Finished
+		}
+		st.Elapsed = end.Sub(*st.Started).Seconds()
+	}
+	return st
+}
+
+// streamEvents send the job status as server sent events until the job is
+// over or the client goes away
+func (j *job) streamEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+	for {
+		event := "progress"
+		select {
+		case <-j.done:
+			event = "end"
+		default:
+		}
+		data, err := json.Marshal(j.snapshot())
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+		flusher.Flush()
+		if event == "end" {
+			return
+		}
+
+		select {
+		case <-tick.C:
+		case <-j.done:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+var formatContentType = map[string]string{
+	"text": "text/plain; charset=utf-8",
+	"json": "application/x-ndjson",
+	"csv":  "text/csv; charset=utf-8",
+	"tsv":  "text/tab-separated-values; charset=utf-8",
+	"html": "text/html; charset=utf-8",
+}
+
+// results render the groups found so far in format
+func (j *job) results(format string) (string, error) {
+	if _, ok := formatContentType[format]; !ok {
+		return "", fmt.Errorf("unknown format '%s'", format)
+	}
+	j.mu.Lock()
+	groups := append([]*cvc.GroupSet(nil), j.groups...)
+	tables := j.tables
+	j.mu.Unlock()
+
+	opts := j.opts
+	opts.Format = format
+	body, err := formatHeader(&opts)
+	if err != nil {
+		return "", err
+	}
+	for i, g := range groups {
+		s, err := formatGroup(&opts, tables, i+1, g)
+		if err != nil {
+			return "", fmt.Errorf("group %d: %v", i+1, err)
+		}
+		body += s
+	}
+	body += formatFooter(&opts)
+	return body, nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
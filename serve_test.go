@@ -0,0 +1,118 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestServer return a server of a single job slot, with the job data
+// (the test alphabet and words list) in a temporary directory
+func newTestServer(t *testing.T) *server {
+	t.Helper()
+	dir := setTestWords(t, testWordsList)
+	GenVarOpts.MaxGroups, GenVarOpts.TimeToRun, GenVarOpts.Workers = 1, 2, 2
+	GenVarOpts.Format, GenVarOpts.Delimiter = "text", ","
+	GenServeOpts = serveOpts{DataDir: dir, MaxTime: 5, Jobs: 1}
+	return &server{jobs: make(map[string]*job), slots: make(chan struct{}, 1), keep: 2}
+}
+
+// serveRequest send the request to the server and check its answer has
+// the status code and contains the text
+func serveRequest(t *testing.T, srv *server, method, path, body string, code int, contains string) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", srv.handleJobs)
+	mux.HandleFunc("/jobs/", srv.handleJob)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	if w.Code != code || !strings.Contains(w.Body.String(), contains) {
+		t.Errorf("%s %s answered %d '%s', expected %d with '%s'",
+			method, path, w.Code, w.Body.String(), code, contains)
+	}
+	return w
+}
+
+// waitJob wait for the job to be over
+func waitJob(t *testing.T, srv *server, id string) {
+	t.Helper()
+	srv.mu.Lock()
+	j := srv.jobs[id]
+	srv.mu.Unlock()
+	select {
+	case <-j.done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("job %s is not over", id)
+	}
+}
+
+func TestServeJobOpts(t *testing.T) {
+	srv := newTestServer(t)
+
+	serveRequest(t, srv, "POST", "/jobs", `{"workers": 3}`, 400, "option 'workers' can not be set by a job")
+	serveRequest(t, srv, "POST", "/jobs", `{"words": "../words_list.txt"}`, 400, "is not a file of the data directory")
+	serveRequest(t, srv, "POST", "/jobs", `{"max_sets": 0}`, 400, "should be at least 1")
+	serveRequest(t, srv, "POST", "/jobs", `{"time_to_run": 6}`, 400, "time_to_run should be between 1 and 5")
+	serveRequest(t, srv, "POST", "/jobs", `{"freq_scale": "log"}`, 400, "unknown freq_scale 'log'")
+	serveRequest(t, srv, "POST", "/jobs", `max_sets=2`, 400, "invalid character")
+	serveRequest(t, srv, "PUT", "/jobs", "", 405, "method PUT not allowed")
+	serveRequest(t, srv, "GET", "/jobs/7", "", 404, "no job at /jobs/7")
+	if len(srv.jobs) != 0 {
+		t.Errorf("rejected jobs were submitted: %v", srv.jobs)
+	}
+}
+
+func TestServeJobs(t *testing.T) {
+	srv := newTestServer(t)
+
+	serveRequest(t, srv, "POST", "/jobs", `{"words": "words_list.txt", "ipa": false}`, 201, `"id":"1"`)
+	serveRequest(t, srv, "POST", "/jobs", `{"vowels": "none.txt"}`, 201, `"state":"queued"`)
+	waitJob(t, srv, "1")
+	waitJob(t, srv, "2")
+
+	serveRequest(t, srv, "GET", "/jobs/1", "", 200, `"state":"done","groups":1`)
+	serveRequest(t, srv, "GET", "/jobs/2", "", 200, `"error":"failed to load vowels`)
+	serveRequest(t, srv, "GET", "/jobs", "", 200, `[{"id":"1"`)
+	serveRequest(t, srv, "GET", "/jobs/1/results", "", 200, "group completed")
+	serveRequest(t, srv, "GET", "/jobs/1/results?format=csv", "", 200, "group,set,position,word")
+	serveRequest(t, srv, "GET", "/jobs/1/results?format=xml", "", 400, "unknown format 'xml'")
+	serveRequest(t, srv, "GET", "/jobs/1/events", "", 200, "event: end\ndata: {\"id\":\"1\"")
+	serveRequest(t, srv, "GET", "/jobs/1/results/all", "", 404, "no job at /jobs/1/results/all")
+	serveRequest(t, srv, "POST", "/jobs/1/results", "", 404, "no POST /jobs/1/results")
+	// cancelling a job which is over leave it as it is
+	serveRequest(t, srv, "DELETE", "/jobs/1", "", 202, `"state":"done"`)
+
+	w := serveRequest(t, srv, "GET", "/jobs/1/results?format=json", "", 200, `{"index":1,`)
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("json results content type '%s'", ct)
+	}
+}
+
+func TestServeCancelQueued(t *testing.T) {
+	srv := newTestServer(t)
+	// hold the only slot, so the job stay queued until cancelled
+	srv.slots <- struct{}{}
+
+	serveRequest(t, srv, "POST", "/jobs", `{}`, 201, `"state":"queued"`)
+	serveRequest(t, srv, "DELETE", "/jobs/1", "", 202, `"id":"1"`)
+	waitJob(t, srv, "1")
+	serveRequest(t, srv, "GET", "/jobs/1", "", 200, `"state":"cancelled"`)
+}
+
+func TestServeEvict(t *testing.T) {
+	srv := newTestServer(t)
+	srv.keep = 1
+
+	serveRequest(t, srv, "POST", "/jobs", `{}`, 201, `"id":"1"`)
+	waitJob(t, srv, "1")
+	serveRequest(t, srv, "POST", "/jobs", `{}`, 201, `"id":"2"`)
+	waitJob(t, srv, "2")
+
+	// the third job drop the oldest finished job
+	serveRequest(t, srv, "POST", "/jobs", `{}`, 201, `"id":"3"`)
+	serveRequest(t, srv, "GET", "/jobs/1", "", 404, "no job at /jobs/1")
+	serveRequest(t, srv, "GET", "/jobs/2", "", 200, `"state":"done"`)
+	waitJob(t, srv, "3")
+}
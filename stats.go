@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gilwo/wordscvc/cvc"
+)
+
+// setBound is an upper bound on the number of word disjoint sets and the
+// resource limiting it
+type setBound struct {
+	name string
+	sets int
+}
+
+// stats report the words list statistics and the upper bounds on the number
+// of sets the rules allow (the stats command)
+func stats(args []string) error {
+	wmap, tables, err := loadWords(&GenVarOpts.flagOpts)
+	if err != nil {
+		return err
+	}
+	var places []cvc.Placement
+	for _, w := range wmap.Words() {
+		places = append(places, cvc.WordPlacement(w, GenVarOpts.FreqCutoff))
+	}
+
+	onsets := make(map[string]int)
+	codas := make(map[string]int)
+	vowelCount := make(map[string]int)
+	pairs := make(map[[2]string]int)
+	var above int
+	for _, p := range places {
+		onsets[p.Onset]++
+		codas[p.Coda]++
+		vowelCount[p.Vowel]++
+		pairs[[2]string{p.Onset, p.Coda}]++
+		if p.Above {
+			above++
+		}
+	}
+
+	fmt.Printf("words: %d\n", len(places))
+	printCounts(tables, "onset", onsets)
+	printCounts(tables, "coda", codas)
+	printCounts(tables, "vowel", vowelCount)
+	if GenVarOpts.FreqCutoff > 0 {
+		printCounts(tables, fmt.Sprintf("frequency (cutoff %d)", GenVarOpts.FreqCutoff),
+			map[string]int{"above": above, "below": len(places) - above})
+	}
+	printPairs(tables, pairs)
+
+	bounds := setBounds(places)
+	fmt.Printf("\nupper bounds on the number of sets (-W %d -f %d -a %d):\n",
+		GenVarOpts.MaxWords, GenVarOpts.FreqCutoff, GenVarOpts.FreqWordsPerLineAboveCutoff)
+	least := bounds[0]
+	for _, b := range bounds {
+		fmt.Printf("  %-12s %d\n", b.name, b.sets)
+		if b.sets < least.sets {
+			least = b
+		}
+	}
+	if GenVarOpts.MaxSets > least.sets {
+		fmt.Printf("-S %d exceeds the upper bound of %d sets (limited by %s)\n",
+			GenVarOpts.MaxSets, least.sets, least.name)
+	} else {
+		fmt.Printf("-S %d is within the upper bound of %d sets\n",
+			GenVarOpts.MaxSets, least.sets)
+	}
+	return nil
+}
+
+// setBounds return the upper bounds on the number of word disjoint sets of
+// the words, each from a necessary condition of the set rules
+func setBounds(places []cvc.Placement) []setBound {
+	words := GenVarOpts.MaxWords
+	fabove := GenVarOpts.FreqWordsPerLineAboveCutoff
+	var above int
+	vowelCount := make(map[string]int)
+	consonantCount := make(map[string]int)
+	perWord := 2 // distinct consonants per word
+	for _, p := range places {
+		if p.Above {
+			above++
+		}
+		vowelCount[p.Vowel]++
+		consonantCount[p.Onset]++
+		if p.Coda != p.Onset {
+			consonantCount[p.Coda]++
+		} else {
+			perWord = 1
+		}
+	}
+
+	bounds := []setBound{{"words", len(places) / words}}
+	if GenVarOpts.FreqCutoff > 0 {
+		if fabove > 0 {
+			bounds = append(bounds, setBound{"above cutoff", above / fabove})
+		}
+		if words > fabove {
+			bounds = append(bounds, setBound{"below cutoff", (len(places) - above) / (words - fabove)})
+		}
+	}
+
+	// k sets use a vowel at most twice each and a consonant at most once each
+	fits := func(counts map[string]int, perSet, need int) func(int) bool {
+		return func(k int) bool {
+			var n int
+			for _, c := range counts {
+				if c > perSet*k {
+					c = perSet * k
+				}
+				n += c
+			}
+			return n >= need*k
+		}
+	}
+	bounds = append(bounds,
+		setBound{"vowels", maxSets(len(places)/words, fits(vowelCount, 2, words))},
+		setBound{"consonants", maxSets(len(places)/words, fits(consonantCount, 1, perWord*words))})
+	return bounds
+}
+
+// maxSets return the largest k up to limit for which fit holds
+func maxSets(limit int, fit func(int) bool) int {
+	k := 0
+	for k < limit && fit(k+1) {
+		k++
+	}
+	return k
+}
+
+// printCounts print the counts by name in name order, with a bar scaled to
+// the largest count
+func printCounts(tables *wordTables, title string, counts map[string]int) {
+	var names []string
+	most := 0
+	for name, n := range counts {
+		names = append(names, name)
+		if n > most {
+			most = n
+		}
+	}
+	sort.Strings(names)
+
+	fmt.Printf("\n%s:\n", title)
+	for _, name := range names {
+		bar := 0
+		if most > 0 {
+			bar = counts[name] * 40 / most
+		}
+		fmt.Printf("  %-8s %5d %s\n", tables.showPhoneme(name), counts[name], strings.Repeat("#", bar))
+	}
+}
+
+// printPairs print the onset (rows) by coda (columns) matrix of the words
+// count, "." marks a pair with no word
+func printPairs(tables *wordTables, pairs map[[2]string]int) {
+	var names []string
+	for c := range tables.consonants {
+		names = append(names, c)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("\nonset/coda pairs: %d of %d covered\n", len(pairs), len(names)*len(names))
+	fmt.Printf("%4s", "")
+	for _, c2 := range names {
+		fmt.Printf("%4s", c2)
+	}
+	fmt.Println()
+	for _, c1 := range names {
+		fmt.Printf("%4s", c1)
+		for _, c2 := range names {
+			if n := pairs[[2]string{c1, c2}]; n > 0 {
+				fmt.Printf("%4d", n)
+			} else {
+				fmt.Printf("%4s", ".")
+			}
+		}
+		fmt.Println()
+	}
+}
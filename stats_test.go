@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gilwo/wordscvc/cvc"
+)
+
+func TestStatsBounds(t *testing.T) {
+	setTestWords(t, testWordsList)
+
+	var places []cvc.Placement
+	for _, w := range []*cvc.Word{
+		cvc.NewWord("N", "O", "P", 30), cvc.NewWord("B", "A", "D", 5),
+		cvc.NewWord("M", "I", "L", 40), cvc.NewWord("D", "A", "B", 7),
+		cvc.NewWord("G", "E", "K", 9)} {
+		places = append(places, cvc.WordPlacement(w, GenVarOpts.FreqCutoff))
+	}
+	// BAD and DAB share their consonants, so only 2 sets have distinct ones
+	expected := "[{words 2} {above cutoff 2} {below cutoff 3} {vowels 2} {consonants 2}]"
+	if actual := fmt.Sprint(setBounds(places)); actual != expected {
+		t.Errorf("set bounds: expected '%s', actual '%s'", expected, actual)
+	}
+
+	GenVarOpts.FreqCutoff = 0
+	expected = "[{words 2} {vowels 2} {consonants 2}]"
+	if actual := fmt.Sprint(setBounds(places)); actual != expected {
+		t.Errorf("set bounds with no cutoff: expected '%s', actual '%s'", expected, actual)
+	}
+}
+
+func TestStats(t *testing.T) {
+	setTestWords(t, testWordsList)
+
+	out, err := captureOutput(t, func() error { return stats(nil) })
+	if err != nil {
+		t.Fatalf("stats failed: %v", err)
+	}
+	for _, part := range []string{
+		"words: 10\n", "\nonset:\n  B            1 #", "  above        2 #",
+		"onset/coda pairs: 10 of 100 covered\n",
+		"-S 2 is within the upper bound of 2 sets\n"} {
+		if !strings.Contains(out, part) {
+			t.Errorf("stats report is missing '%s': '%s'", part, out)
+		}
+	}
+
+	GenVarOpts.MaxSets = 3
+	if out, _ = captureOutput(t, func() error { return stats(nil) }); !strings.Contains(out,
+		"-S 3 exceeds the upper bound of 2 sets (limited by above cutoff)\n") {
+		t.Errorf("stats report does not tell the group is not possible: '%s'", out)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// transliteration table, map the phonetic symbols of the alphabet to a
+// script (see hebrew.txt for the format)
+
+type translitForm struct {
+	plain, final          string
+	pointed, pointedFinal string
+}
+
+type translitTable struct {
+	consonants map[string]translitForm
+	vowels     map[string]translitForm
+}
+
+// newTranslitForm parse "letter[/final]" and the optional pointed form
+func newTranslitForm(fields []string) translitForm {
+	split := func(s string) (string, string) {
+		if s == "-" {
+			return "", ""
+		}
+		parts := strings.SplitN(s, "/", 2)
+		if len(parts) == 1 {
+			return s, s
+		}
+		return parts[0], parts[1]
+	}
+
+	var f translitForm
+	f.plain, f.final = split(fields[0])
+	f.pointed, f.pointedFinal = f.plain, f.final
+	if len(fields) > 1 {
+		f.pointed, f.pointedFinal = split(fields[1])
+	}
+	return f
+}
+
+// loadTranslit read a transliteration table from fname
+func loadTranslit(fname string) (*translitTable, error) {
+	t := &translitTable{
+		consonants: make(map[string]translitForm),
+		vowels:     make(map[string]translitForm),
+	}
+	lines, err := getLinesFromFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range lines {
+		if c := strings.Index(line, "#"); c != -1 {
+			line = line[:c]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 3 || len(fields) > 4 {
+			return nil, fmt.Errorf("%s:%d: expected 'c|v SYMBOL letters [pointed]'", fname, i+1)
+		}
+		switch fields[0] {
+		case "c":
+			t.consonants[fields[1]] = newTranslitForm(fields[2:])
+		case "v":
+			t.vowels[fields[1]] = newTranslitForm(fields[2:])
+		default:
+			return nil, fmt.Errorf("%s:%d: unknown role '%s', expected c or v", fname, i+1, fields[0])
+		}
+	}
+	return t, nil
+}
+
+// render spell the word made of c1, v and c2 in the transliteration table
+// script, with niqqud when pointed is set
+func (t *wordTables) render(c1, v, c2 string, pointed bool) (string, error) {
+	onset, ok := t.translit.consonants[c1]
+	if !ok {
+		return "", fmt.Errorf("no transliteration for consonant '%s'", t.showPhoneme(c1))
+	}
+	vowel, ok := t.translit.vowels[v]
+	if !ok {
+		return "", fmt.Errorf("no transliteration for vowel '%s'", t.showPhoneme(v))
+	}
+	coda, ok := t.translit.consonants[c2]
+	if !ok {
+		return "", fmt.Errorf("no transliteration for consonant '%s'", t.showPhoneme(c2))
+	}
+
+	if pointed {
+		return onset.pointed + vowel.pointed + coda.pointedFinal, nil
+	}
+	return onset.plain + vowel.plain + coda.final, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// testTranslit load a small transliteration table
+func testTranslit(t *testing.T) *translitTable {
+	t.Helper()
+	translit, err := loadTranslit(writeTestFile(t, "hebrew.txt", `# test table
+c SH ש שׁ
+c M  מ/ם
+c R  ר
+c K  כ/ך     כּ/ךּ
+v O  ו       וֹ
+v A  -       ַ
+`))
+	if err != nil {
+		t.Fatalf("failed to load the transliteration table: %v", err)
+	}
+	return translit
+}
+
+func TestTranslitRender(t *testing.T) {
+	tables := &wordTables{translit: testTranslit(t)}
+
+	hebrew, err := tables.render("SH", "O", "M", false)
+	if err != nil || hebrew != "שום" {
+		t.Errorf("SHOM spelled '%s', %v", hebrew, err)
+	}
+	// the vowel A has no letter, only niqqud
+	if hebrew, _ = tables.render("M", "A", "R", false); hebrew != "מר" {
+		t.Errorf("MAR spelled '%s'", hebrew)
+	}
+	if hebrew, _ = tables.render("K", "A", "K", true); hebrew != "\u05db\u05bc\u05b7\u05da\u05bc" {
+		t.Errorf("KAK spelled with niqqud '%s'", hebrew)
+	}
+	if hebrew, _ = tables.render("SH", "O", "R", true); hebrew != "שׁוֹר" {
+		t.Errorf("SHOR spelled with niqqud '%s'", hebrew)
+	}
+
+	if _, err = tables.render("T", "O", "R", false); err == nil ||
+		err.Error() != "no transliteration for consonant 'T'" {
+		t.Errorf("consonant with no letter: %v", err)
+	}
+	if _, err = tables.render("R", "U", "M", false); err == nil ||
+		err.Error() != "no transliteration for vowel 'U'" {
+		t.Errorf("vowel with no letter: %v", err)
+	}
+}
+
+func TestTranslitLoad(t *testing.T) {
+	if _, err := loadTranslit(writeTestFile(t, "hebrew.txt", "c SH\n")); err == nil ||
+		!strings.Contains(err.Error(), ":1: expected 'c|v SYMBOL letters [pointed]'") {
+		t.Errorf("table line with no letters: %v", err)
+	}
+	if _, err := loadTranslit(writeTestFile(t, "hebrew.txt", "# x\nx SH ש\n")); err == nil ||
+		!strings.Contains(err.Error(), ":2: unknown role 'x'") {
+		t.Errorf("table line with an unknown role: %v", err)
+	}
+}
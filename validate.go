@@ -0,0 +1,317 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gilwo/wordscvc/cvc"
+)
+
+// fileGroup is a group read back from a groups file, its sets hold the words
+// as written
+type fileGroup struct {
+	index int
+	sets  [][]string
+}
+
+// validate replay the groups of a groups file through the set and group
+// rules and report every violation (the validate command)
+func validate(args []string) error {
+	fname := GenValidateOpts.Args.GroupsFile
+	groups, err := readGroups(fname, GenValidateOpts.Format)
+	if err != nil {
+		return err
+	}
+
+	wmap, tables, err := loadWords(&GenVarOpts.flagOpts)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]*cvc.Word)
+	for _, w := range wmap.Words() {
+		byName[w.String()] = w
+	}
+
+	violations := 0
+	for _, g := range groups {
+		for _, msg := range validateGroup(tables, g, byName) {
+			fmt.Printf("group %d %s\n", g.index, msg)
+			violations++
+		}
+	}
+	fmt.Printf("%d groups checked, %d violations\n", len(groups), violations)
+	if violations > 0 {
+		return fmt.Errorf("%s does not obey the rules", fname)
+	}
+	return nil
+}
+
+// validateGroup replay the group sets word by word and return the rule
+// violations found
+func validateGroup(tables *wordTables, g fileGroup, byName map[string]*cvc.Word) []string {
+	var out []string
+	report := func(set int, name string, w *cvc.Word, f string, v ...interface{}) {
+		if w != nil {
+			ipa, _ := w.Meta("ipa")
+			name = tables.showWord(name, ipa)
+		}
+		out = append(out, fmt.Sprintf("set %d word %s: ", set, name)+fmt.Sprintf(f, v...))
+	}
+
+	group := cvc.NewGroupSetLimitFreq(GenVarOpts.MaxSets, GenVarOpts.MaxWords,
+		GenVarOpts.FreqCutoff, GenVarOpts.FreqWordsPerLineAboveCutoff)
+	where := make(map[string]int)
+	for i, names := range g.sets {
+		set := cvc.NewSetLimitFreq(GenVarOpts.MaxWords,
+			GenVarOpts.FreqCutoff, GenVarOpts.FreqWordsPerLineAboveCutoff)
+		var words cvc.WordList
+		for _, name := range names {
+			w, ok := byName[name]
+			if !ok {
+				report(i+1, name, nil, "not in the words list")
+				continue
+			}
+			if prev, ok := where[name]; ok {
+				report(i+1, name, w, "already used in set %d", prev)
+				continue
+			}
+			where[name] = i + 1
+			if err := set.AddWordErr(w); err != nil {
+				report(i+1, name, w, "%v", err)
+				continue
+			}
+			words = append(words, w)
+		}
+		if len(names) != GenVarOpts.MaxWords {
+			out = append(out, fmt.Sprintf("set %d: has %d words, expected %d",
+				i+1, len(names), GenVarOpts.MaxWords))
+		}
+		if _, full := group.AddSet(words); full {
+			out = append(out, fmt.Sprintf("set %d: group already has %d sets",
+				i+1, GenVarOpts.MaxSets))
+		}
+	}
+	if len(g.sets) < GenVarOpts.MaxSets {
+		out = append(out, fmt.Sprintf("has %d sets, expected %d",
+			len(g.sets), GenVarOpts.MaxSets))
+	}
+	return out
+}
+
+// readGroups read the groups of fname written in format, auto guess the
+// format from the file extension
+func readGroups(fname, format string) ([]fileGroup, error) {
+	if format == "auto" {
+		switch strings.ToLower(filepath.Ext(fname)) {
+		case ".json", ".jsonl":
+			format = "json"
+		case ".csv":
+			format = "csv"
+		case ".tsv":
+			format = "tsv"
+		case ".html", ".htm":
+			format = "html"
+		default:
+			format = "text"
+		}
+	}
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	delim := '\t'
+	if format == "csv" && GenValidateOpts.Delimiter != "tab" {
+		r := []rune(GenValidateOpts.Delimiter)
+		if len(r) != 1 {
+			return nil, fmt.Errorf("delimiter '%s' is not a single character",
+				GenValidateOpts.Delimiter)
+		}
+		delim = r[0]
+	}
+	groups, err := parseGroups(string(data), format, delim)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fname, err)
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("%s: no groups found", fname)
+	}
+	return groups, nil
+}
+
+// parseGroups parse the groups of data written in format, delim is the
+// field delimiter of the csv/tsv formats
+func parseGroups(data, format string, delim rune) ([]fileGroup, error) {
+	switch format {
+	case "json":
+		return readJSONGroups(data)
+	case "csv", "tsv":
+		return readCSVGroups(data, delim)
+	case "html":
+		return readHTMLGroups(data), nil
+	}
+	return readTextGroups(data), nil
+}
+
+var textSetLine = regexp.MustCompile(`^\s*(\d+):\[(.*)\]\s*$`)
+
+// readTextGroups read the text output format, each set is a "N:[WORD:freq,
+// ...]" line and a group starts at "group completed" or at set 1
+func readTextGroups(data string) []fileGroup {
+	var groups []fileGroup
+	newGroup := true
+	for _, line := range strings.Split(data, "\n") {
+		if strings.TrimSpace(line) == "group completed" {
+			newGroup = true
+			continue
+		}
+		m := textSetLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if newGroup || m[1] == "1" {
+			groups = append(groups, fileGroup{index: len(groups) + 1})
+			newGroup = false
+		}
+
+		var set []string
+		for _, tok := range strings.Split(m[2], ", ") {
+			// drop the hebrew spelling, ipa and frequency shown next to the word
+			if end := strings.IndexAny(tok, "( :"); end != -1 {
+				tok = tok[:end]
+			}
+			if tok = strings.TrimSpace(tok); tok != "" {
+				set = append(set, tok)
+			}
+		}
+		g := &groups[len(groups)-1]
+		g.sets = append(g.sets, set)
+	}
+	return groups
+}
+
+// readJSONGroups read the json output format, one group object per line,
+// bare group objects are accepted as well
+func readJSONGroups(data string) ([]fileGroup, error) {
+	type jsonGroup struct {
+		Sets []struct {
+			Words []struct {
+				Word string `json:"word"`
+			} `json:"words"`
+		} `json:"sets"`
+	}
+	var groups []fileGroup
+	dec := json.NewDecoder(strings.NewReader(data))
+	for {
+		var rec struct {
+			Index int        `json:"index"`
+			Group *jsonGroup `json:"group"`
+			jsonGroup
+		}
+		err := dec.Decode(&rec)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if rec.Group == nil {
+			rec.Group = &rec.jsonGroup
+		}
+		if rec.Index == 0 {
+			rec.Index = len(groups) + 1
+		}
+
+		g := fileGroup{index: rec.Index}
+		for _, s := range rec.Group.Sets {
+			var set []string
+			for _, w := range s.Words {
+				set = append(set, w.Word)
+			}
+			g.sets = append(g.sets, set)
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+// readCSVGroups read the csv/tsv output format by column position (group,
+// set, position, word, ...), the header row is skipped whatever its names
+func readCSVGroups(data string, delim rune) ([]fileGroup, error) {
+	r := csv.NewReader(strings.NewReader(data))
+	r.Comma = delim
+	r.FieldsPerRecord = -1
+
+	var groups []fileGroup
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+		if len(rec) < 4 {
+			return nil, fmt.Errorf("line %d: expected group, set, position and word columns", line)
+		}
+		index, err := strconv.Atoi(rec[0])
+		if err != nil {
+			if len(groups) == 0 {
+				continue // header
+			}
+			return nil, fmt.Errorf("line %d: group '%s' is not a number", line, rec[0])
+		}
+		set, err := strconv.Atoi(rec[1])
+		if err != nil || set < 1 {
+			return nil, fmt.Errorf("line %d: set '%s' is not a set number", line, rec[1])
+		}
+
+		if len(groups) == 0 || groups[len(groups)-1].index != index {
+			groups = append(groups, fileGroup{index: index})
+		}
+		g := &groups[len(groups)-1]
+		for len(g.sets) < set {
+			g.sets = append(g.sets, nil)
+		}
+		g.sets[set-1] = append(g.sets[set-1], rec[3])
+	}
+	return groups, nil
+}
+
+var htmlPageTitle = regexp.MustCompile(`<h1>group (\d+) &middot; set (\d+)</h1>`)
+var htmlWordItem = regexp.MustCompile(`<li>([^<]*)(?:<span class="translit" dir="ltr">([^<]*)</span>)?`)
+
+// readHTMLGroups read the html worksheet output format, one page per set,
+// the transliteration is the word when the page is in hebrew script
+func readHTMLGroups(data string) []fileGroup {
+	var groups []fileGroup
+	for _, page := range strings.Split(data, `<div class="page"`)[1:] {
+		m := htmlPageTitle.FindStringSubmatch(page)
+		if m == nil {
+			continue
+		}
+		index, _ := strconv.Atoi(m[1])
+		if len(groups) == 0 || groups[len(groups)-1].index != index {
+			groups = append(groups, fileGroup{index: index})
+		}
+
+		var set []string
+		for _, item := range htmlWordItem.FindAllStringSubmatch(page, -1) {
+			word := item[2]
+			if word == "" {
+				word = item[1]
+			}
+			set = append(set, strings.TrimSpace(html.UnescapeString(word)))
+		}
+		g := &groups[len(groups)-1]
+		g.sets = append(g.sets, set)
+	}
+	return groups
+}
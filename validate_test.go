@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gilwo/wordscvc/cvc"
+)
+
+func TestValidate(t *testing.T) {
+	setTestWords(t, testWordsList)
+	GenValidateOpts.Format = "auto"
+
+	GenValidateOpts.Args.GroupsFile = writeTestFile(t, "groups.txt",
+		"group completed\n\n\t1:[NOP:30, BAD:5]\n\t2:[MIL:40, GEK:9]\n")
+	out, err := captureOutput(t, func() error { return validate(nil) })
+	if err != nil || out != "1 groups checked, 0 violations\n" {
+		t.Errorf("valid group: '%s', %v", out, err)
+	}
+
+	GenValidateOpts.Args.GroupsFile = writeTestFile(t, "groups.txt",
+		"\t1:[NOP:30, MIL:40]\n\t2:[NOP:30, ZUG:1]\n")
+	out, err = captureOutput(t, func() error { return validate(nil) })
+	for _, line := range []string{
+		"group 1 set 1 word MIL: ",
+		"group 1 set 2 word NOP: already used in set 1\n",
+		"group 1 set 2 word ZUG: not in the words list\n",
+		"1 groups checked, 3 violations\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("invalid group report is missing '%s': '%s'", line, out)
+		}
+	}
+	if err == nil || !strings.HasSuffix(err.Error(), "groups.txt does not obey the rules") {
+		t.Errorf("invalid group error: %v", err)
+	}
+}
+
+// the groups are read back from every output format
+func TestValidateFormats(t *testing.T) {
+	group := cvc.NewGroupSetLimit(2, 2)
+	for _, w := range []*cvc.Word{
+		cvc.NewWordMeta("N", "O", "P", 30, map[string]string{"hebrew": "נופ"}), cvc.NewWord("B", "A", "D", 5),
+		cvc.NewWord("M", "I", "L", 40)} {
+		group.AddWord(w)
+	}
+
+	for _, format := range []string{"text", "json", "csv", "tsv", "html"} {
+		opts := flagOpts{}
+		opts.Format, opts.Delimiter, opts.Hebrew = format, ";", true
+		header, _ := formatHeader(&opts)
+		out1, err := formatGroup(&opts, &wordTables{}, 1, group)
+		if err != nil {
+			t.Fatalf("failed to format the %s group: %v", format, err)
+		}
+		out2, _ := formatGroup(&opts, &wordTables{}, 2, group)
+
+		GenValidateOpts.Delimiter = ";"
+		groups, err := readGroups(writeTestFile(t, "groups."+format,
+			header+out1+out2+formatFooter(&opts)), "auto")
+		if err != nil {
+			t.Errorf("failed to read the %s groups: %v", format, err)
+			continue
+		}
+		if len(groups) != 2 || groups[1].index != 2 ||
+			fmt.Sprint(groups[0].sets) != "[[NOP BAD] [MIL]]" {
+			t.Errorf("%s groups read as %v", format, groups)
+		}
+	}
+
+	if _, err := readGroups(writeTestFile(t, "groups.txt", "no groups\n"), "auto"); err == nil ||
+		!strings.HasSuffix(err.Error(), "groups.txt: no groups found") {
+		t.Errorf("file with no groups: %v", err)
+	}
+}
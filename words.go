@@ -2,49 +2,102 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"runtime/pprof"
 	"sort"
 	"strconv"
 	"strings"
-	"time"
+	"sync/atomic"
+	"syscall"
 
 	"github.com/gilwo/wordscvc/cvc"
 	"github.com/gilwo/workqueue/pool"
 	"github.com/jessevdk/go-flags"
 )
 
-type flagOpts struct {
-	// flag able vars
-	MaxGroups                   int     `short:"G" description:"1  number of result groups to generate" default:"20"`
-	MaxSets                     int     `short:"S" description:"2  number of sets per group" default:"15"`
-	MaxWords                    int     `short:"W" description:"3  number of words per set" default:"10"`
-	FreqCutoff                  int     `short:"f" description:"4  frequency cutoff threshold for words, lower is more common" default:"25"`
-	FreqWordsPerLineAboveCutoff int     `short:"a" description:"5  how many words to be above cutoff threshold per line" default:"3"`
-	VowelLimit                  int     `long:"vowel" description:"6  how many time each vowel repeat per set" hidden:"1"`// 2
+// ruleOpts are the set and group rules options
+type ruleOpts struct {
+	MaxSets                     int     `short:"S" description:"22 number of sets per group" default:"15" json:"max_sets"`
+	MaxWords                    int     `short:"W" description:"23 number of words per set" default:"10" json:"max_words"`
+	FreqCutoff                  int     `short:"f" description:"24 frequency cutoff threshold for words (in the frequency scale unit), higher is more common" default:"25" json:"freq_cutoff"`
+	FreqWordsPerLineAboveCutoff int     `short:"a" description:"25 how many words to be above cutoff threshold (more common) per line" default:"3" json:"freq_above"`
+	VowelLimit                  int     `long:"vowel" description:"26 how many time each vowel repeat per set" hidden:"1" json:"vowel_limit"`// 2
+}
 
-	InConsonantFile             string  `short:"C" description:"7  input file name for consonants to use" optional:"1" default:"consonants.txt"`
-	InVowelFile                 string  `short:"V" description:"8  input file name for vowels to use" optional:"1" default:"vowels.txt"`
-	InWordsFile                 string  `short:"i" description:"9  input file name for words list to use for creating the lines groups results" optional:"1" default:"words_list.txt"`
+// alphabetOpts are the alphabet options
+type alphabetOpts struct {
+	InConsonantFile             string  `short:"C" description:"27 input file name for consonants to use" optional:"1" default:"consonants.txt" json:"consonants"`
+	InVowelFile                 string  `short:"V" description:"28 input file name for vowels to use" optional:"1" default:"vowels.txt" json:"vowels"`
+}
+
+// lexiconOpts are the words list options
+type lexiconOpts struct {
+	InWordsFile                 string  `short:"i" description:"29 input file name for words list to use for creating the lines groups results (text, .json, .csv or .tsv lexicon)" optional:"1" default:"words_list.txt" json:"words"`
+	FreqScale                   string  `long:"freq-scale" description:"30 frequency scale the words list counts are converted to when loaded: raw count, per-million rate and zipf (per-million log10 + 3) both in hundredths (-f 425 is zipf 4.25), or rank (percentile, 100 is the most common)" choice:"raw" choice:"per-million" choice:"zipf" choice:"rank" default:"raw" json:"freq_scale"`
 
-	FilterFile                  string  `short:"F" description:"10 input file name for filtered words"`
-	OutResultFile               string  `short:"o" description:"11 output file for generated results" default:"words_result.txt" default-mask:"-"`
+	FilterFile                  string  `short:"F" description:"31 input file name for words filter rules (include/exclude words or c1/v/c2 patterns)" json:"filter"`
 
-	TimeToRun                   int     `short:"t" description:"12 how much time to run (in seconds)" default:"30"`
+	TranslitFile                string  `long:"translit" description:"32 input file name for the transliteration table used to spell the words in hebrew script" json:"translit"`
+	Niqqud                      bool    `long:"niqqud" description:"33 spell the words in hebrew script with niqqud" json:"niqqud"`
+}
 
-	CpuProfile                  string  `short:"c" description:"13 enable cpu profiling and save to file"`
-	MemProfile                  string  `short:"m" description:"14 enable memory profiling and save to file"`
+// displayOpts are the options of the words display
+type displayOpts struct {
+	IPA                         bool    `long:"ipa" description:"34 show the ipa transcription in the outputs and messages" json:"ipa"`
+}
 
-	DebugEnabled                bool    `short:"d" description:"15 enable debugging information"`
-	Verbose                     []bool  `short:"v" description:"16 show verbose information"`
+// globalOpts are the options of all the commands
+type globalOpts struct {
+	Profile                     string  `long:"profile" description:"1  language profile directory or manifest file, setting the alphabet, words, transliteration and rule defaults" json:"profile"`
 
-	UsePool                     bool    `short:"p" description:"17 enable using the worker pool logic" hidden:"1"`
-	UseJobDispose               bool    `short:"D" description:"18 enable using the worker job dispose logic" hidden:"1"`
-	Workers                     uint    `short:"w" description:"19 how many workers to use" default:"30" hidden:"1"`
+	DebugEnabled                bool    `short:"d" description:"2  enable debugging information" json:"debug"`
+	Verbose                     []bool  `short:"v" description:"3  show verbose information" json:"verbose"`
+}
 
+// sharedOpts are the option groups taken by more than one command, each
+// command parse the groups it takes into its own copy (see commands.go)
+type sharedOpts struct {
+	ruleOpts
+	alphabetOpts
+	lexiconOpts
+	displayOpts
+	globalOpts
+}
+
+// generateOpts are the options of the generate command
+type generateOpts struct {
+	MaxGroups                   int     `short:"G" description:"6  number of result groups to generate" default:"20" json:"max_groups"`
+
+	OutResultFile               string  `short:"o" description:"7  output file for generated results" default:"words_result.txt" default-mask:"-" json:"output"`
+	SeedFile                    string  `short:"s" description:"8  input file name for fixed sets (one set per line, or a saved json group) to complete into a group" json:"seed"`
+	AppendResult                bool    `long:"append" description:"9  append the results to the existing output file instead of overwriting it" json:"append"`
+	Format                      string  `long:"format" description:"10 output format of the results" choice:"text" choice:"json" choice:"csv" choice:"tsv" choice:"html" default:"text" json:"format"`
+	Delimiter                   string  `long:"delimiter" description:"11 field delimiter for the csv output format ('tab' for a tab)" default:"," json:"delimiter"`
+	Header                      string  `long:"header" description:"12 comma separated column names for the csv/tsv header, 'none' to omit the header" json:"header"`
+	HTMLFreq                    bool    `long:"html-freq" description:"13 show the words frequency in the html output" json:"html_freq"`
+	HTMLMarks                   bool    `long:"html-marks" description:"14 mark the words above the frequency cutoff in the html output" json:"html_marks"`
+	Hebrew                      bool    `long:"hebrew" description:"15 show the hebrew spelling next to the transliteration in the text, csv and html outputs" json:"hebrew"`
+
+	TimeToRun                   int     `short:"t" description:"16 how much time to run (in seconds)" default:"30" json:"time_to_run"`
+
+	CpuProfile                  string  `short:"c" description:"17 enable cpu profiling and save to file" json:"cpu_profile"`
+	MemProfile                  string  `short:"m" description:"18 enable memory profiling and save to file" json:"mem_profile"`
+
+	UsePool                     bool    `short:"p" description:"19 enable using the worker pool logic" hidden:"1" json:"use_pool"`
+	UseJobDispose               bool    `short:"D" description:"20 enable using the worker job dispose logic" hidden:"1" json:"use_job_dispose"`
+	Workers                     uint    `short:"w" description:"21 how many workers to use" default:"30" hidden:"1" json:"workers"`
+}
+
+type flagOpts struct {
+	// flag able vars
+	sharedOpts
+	generateOpts
 }
 
 func (fo flagOpts) String() string {
@@ -54,6 +107,7 @@ func (fo flagOpts) String() string {
 		"\tmax words: '%v'\n"+
 		"\tfrequency cutoff: '%v'\n"+
 		"\tabove frequency words per set: '%v'\n"+
+		"\tfrequency scale: '%v'\n"+
 		//"\tvowels limit: '%v'\n"+
 		"\n"+
 		"\tconsonant file : '%v'\n"+
@@ -61,7 +115,20 @@ func (fo flagOpts) String() string {
 		"\twords file: '%v'\n"+
 		"\n"+
 		"\tfilter file: '%v'\n"+
+		"\tseed file: '%v'\n"+
 		"\tresult output file: '%v'\n"+
+		"\tappend results: '%v'\n"+
+		"\toutput format: '%v'\n"+
+		"\tcsv delimiter: '%v'\n"+
+		"\tcsv header: '%v'\n"+
+		"\thtml frequencies: '%v'\n"+
+		"\thtml above cutoff marks: '%v'\n"+
+		"\n"+
+		"\ttransliteration file: '%v'\n"+
+		"\tniqqud: '%v'\n"+
+		"\tshow hebrew: '%v'\n"+
+		"\tshow ipa: '%v'\n"+
+		"\tprofile: '%v'\n"+
 		"\n"+
 		"\ttime to run: '%v'\n"+
 		"\n"+
@@ -77,12 +144,25 @@ func (fo flagOpts) String() string {
 		fo.MaxWords,
 		fo.FreqCutoff,
 		fo.FreqWordsPerLineAboveCutoff,
+		fo.FreqScale,
 		//fo.VowelLimit,
 		fo.InConsonantFile,
 		fo.InVowelFile,
 		fo.InWordsFile,
 		fo.FilterFile,
+		fo.SeedFile,
 		fo.OutResultFile,
+		fo.AppendResult,
+		fo.Format,
+		fo.Delimiter,
+		fo.Header,
+		fo.HTMLFreq,
+		fo.HTMLMarks,
+		fo.TranslitFile,
+		fo.Niqqud,
+		fo.Hebrew,
+		fo.IPA,
+		fo.Profile,
 		fo.TimeToRun,
 		fo.Workers,
 		fo.UsePool,
@@ -95,46 +175,41 @@ func (fo flagOpts) String() string {
 
 type varOpts struct {
 	flagOpts
-
-	// internal vars
-	countGroups    int
-	finishSignal   bool
-	maxWorkers     int
-	currentWorkers int
 }
 
 var GenVarOpts varOpts
+var GenConfigOpts configOpts
 var pool *workerpool.WPool
 
-var consonants, vowels map[string]int
-
-var waitForWorkers = make(chan bool)
-var collectingDone = make(chan struct{})
-var msgs = make(chan string, 100)
-var startedWorkers = make(chan struct{}, 100)
-var stoppedWorkers = make(chan struct{}, 100)
-var maxSize int = 0
-var disposeChan = make (chan *workerpool.WorkerJob, 1000)
-var disposeDone = make (chan bool)
+// wordTables are the alphabet (with the phonemes ipa symbols) and the
+// transliteration table the words are loaded and shown with, each run (or
+// serve job) loads its own
+type wordTables struct {
+	consonants, vowels map[string]int
+	ipa                map[string]string
+	translit           *translitTable // nil when none is given
+	showIPA            bool           // show the ipa symbols of the phonemes and words
+}
 
 type findArg struct {
 	group *cvc.GroupSet
 	wordmap *cvc.WordMap
 }
 
-func findGroups(iarg interface{}, job *workerpool.WorkerJob, stop workerpool.CheckStop) (none interface{}) {
+func (s *search) findGroups(iarg interface{}, job *workerpool.WorkerJob, stop workerpool.CheckStop) (none interface{}) {
 	arg, _ := iarg.(findArg)
 
 	defer func() {
 		if fail := recover(); fail != nil {
-			verbose("recovered from %s\n", fail)
+			s.opts.verbose("recovered from %s\n", fail)
 		}
-		stoppedWorkers <- struct{}{}
+		s.stoppedWorkers <- struct{}{}
 		arg.group = nil
 		arg.wordmap = nil
-		if GenVarOpts.UsePool && GenVarOpts.UseJobDispose {
-			if !GenVarOpts.finishSignal {
-				disposeChan <- job
+		if s.opts.UsePool && s.opts.UseJobDispose {
+			select {
+			case s.disposeChan <- job:
+			case <-s.quit:
 			}
 		}
 
@@ -142,92 +217,111 @@ func findGroups(iarg interface{}, job *workerpool.WorkerJob, stop workerpool.Che
 		return
 	}()
 
-	startedWorkers <- struct{}{}
-	zmap := *arg.wordmap.GetCm()
+	s.startedWorkers <- struct{}{}
 	if !arg.group.Checkifavailable(arg.wordmap) {
 		return
 	}
-	if float64(arg.group.CurrentSize())/float64(arg.group.MaxSize()) > float64(0.9) {
-		s := fmt.Sprintf("status: reached depth %d of %d\n",
-			int(arg.group.CurrentSize()), int(arg.group.MaxSize()))
-		msgs <- s
+	placed := arg.group.WordCount()
+	if float64(placed)/float64(arg.group.MaxSize()) > float64(0.9) {
+		s.send(fmt.Sprintf("status: reached depth %d of %d\n",
+			placed, arg.group.MaxSize()))
 	}
-	if arg.group.CurrentSize() > maxSize {
-		msgs <- "depth: " + strconv.Itoa(arg.group.CurrentSize())
+	if int64(placed) > atomic.LoadInt64(&s.maxSize) {
+		s.send("depth: " + strconv.Itoa(placed))
 	}
 
-Loop:
-	for k := range zmap {
+	// the words are tried from a random one, so each run explore the groups
+	// in another order
+	arg.wordmap.Range(func(k *cvc.Word) bool {
 
-		if GenVarOpts.finishSignal {
-			 info("finishSignal issued, exiting\n")
-			break Loop
+		if s.finished() {
+			 s.opts.info("finishSignal issued, exiting\n")
+			return false
 		}
-		if GenVarOpts.countGroups >= GenVarOpts.MaxGroups {
-			info("groups count %d reached max groups %d", GenVarOpts.countGroups, GenVarOpts.MaxGroups)
-			break Loop
+		if count := atomic.LoadInt64(&s.countGroups); count >= int64(s.opts.MaxGroups) {
+			s.opts.info("groups count %d reached max groups %d", count, s.opts.MaxGroups)
+			return false
 		}
 		if added, full := arg.group.AddWord(k); full == true {
-			msg := fmt.Sprintf("group completed\n%s\n", arg.group.StringWithFreq())
-			if GenVarOpts.DebugEnabled {
-				msg += arg.group.DumpGroup() + "\n"
+			select {
+			case s.groupsFound <- arg.group:
+			case <-s.quit:
 			}
-			msgs <- msg
-			break Loop
+			return false
 		} else if added {
 			arg.wordmap.DelWord(k)
-			if !GenVarOpts.UsePool {
-				go findGroups(
+			if !s.opts.UsePool {
+				go s.findGroups(
 					findArg{
 						arg.group.CopyGroupSet(),
 						arg.wordmap.CopyWordMap(),
 					}, nil, nil)
 			} else {
-				_, err := pool.NewJobQueue(findGroups,
+				_, err := pool.NewJobQueue(s.findGroups,
 					findArg{
 						arg.group.CopyGroupSet(),
 						arg.wordmap.CopyWordMap(),
 					})
 				if err !=nil {
-					info("error queuing job %v\n", err)
+					s.opts.info("error queuing job %v\n", err)
 				}
-				trace("%v\n", pool.PoolStats())
+				s.opts.trace("%v\n", pool.PoolStats())
 			}
 		}
-	}
+		return true
+	})
 	return
 }
 
 func main() {
-
-	var out string
-
-	a, err := flags.NewParser(&GenVarOpts, flags.Default).Parse()
+	cmd, a, err := parseOpts()
 	if err != nil {
-		if e, ok := err.(*flags.Error); ok {
-			switch e.Type {
-			case flags.ErrHelp:
-				os.Exit(0)
-			default:
-				fmt.Printf("error parsing opts: %v\n", e.Type)
-				os.Exit(1)
-			}
-		}
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+	info("opts:\n%v\ncommand: %s\na:\n%v\n", GenVarOpts, cmd, a)
+
+	switch cmd {
+	case "validate":
+		err = validate(a)
+	case "stats":
+		err = stats(a)
+	case "lint":
+		err = lint(a)
+	case "query":
+		err = query(a)
+	case "corpus":
+		err = corpus(a)
+	case "build":
+		err = build(a)
+	case "serve":
+		err = serve(a)
+	default:
+		err = generate(a)
+	}
+	if err != nil {
+		fmt.Printf("%s: %v\n", cmd, err)
+		os.Exit(1)
 	}
-	info("opts:\n%v\na:\n%v\n", GenVarOpts, a)
+}
+
+// generate search for groups of sets (the generate command, also the
+// default when no command is given)
+func generate(args []string) error {
+
+	var out string
+	var err error
 
 	if GenVarOpts.UsePool {
 		if GenVarOpts.DebugEnabled {
 			workerpool.WorkerPoolSetLogLevel(workerpool.DebugLevel)
 		}
 		if pool, err = workerpool.NewWPool(GenVarOpts.Workers); err != nil {
-			fmt.Printf("failed to create pool %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to create pool %v", err)
 		}
 
 		if _, err = pool.StartDispatcher(); err != nil {
-			fmt.Printf("failed to start pool dispatcher %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to start pool dispatcher %v", err)
 		}
 	}
 
@@ -254,154 +348,120 @@ func main() {
 		defer pprof.StopCPUProfile()
 	}
 
-	consonants = getMap(GenVarOpts.InConsonantFile)
-	vowels = getMap(GenVarOpts.InVowelFile)
-	verbose("consonants: %d\n%s\n", len(consonants), getOrderedMapString(consonants))
-	verbose("vowels: %d\n%s\n", len(vowels), getOrderedMapString(vowels))
-
-	wmap := getWordsMap(GenVarOpts.InWordsFile)
-	verbose("map size: %d\ncontent:\n%s\n", wmap.Size(), wmap)
-
-	// set the base group according to the required settings
-	baseGroup := cvc.NewGroupSetLimitFreq(
-		GenVarOpts.MaxSets,
-		GenVarOpts.MaxWords,
-		GenVarOpts.FreqCutoff,
-		GenVarOpts.FreqWordsPerLineAboveCutoff)
-
-	// start time measuring
-	t0 := time.Now()
-
-	if GenVarOpts.UsePool && GenVarOpts.UseJobDispose {
-		// job disposer
-		go func() {
-			for j := range disposeChan {
-				if j.JobStatus() != workerpool.Jfinished {
-					if !GenVarOpts.finishSignal {
-						disposeChan <- j
-					}
-				} else {
-					j.JobDispose()
-				}
-			}
-			close(disposeDone)
-		}()
+	wmap, tables, err := loadWords(&GenVarOpts.flagOpts)
+	if err != nil {
+		return err
 	}
 
-	// wait for all goroutines to finish
-	go func() {
-		count := 0
-		i := 0
-		for {
-			 verbose("going to wait\n")
-			select {
-			case <-startedWorkers:
-				trace("startedWorkers")
-				count++
-				if count > GenVarOpts.maxWorkers {
-					GenVarOpts.maxWorkers = count
-				}
-				GenVarOpts.currentWorkers = count
-				i = 0
-			case <-stoppedWorkers:
-				// println("stoppedWorkers")
-				count--
-				GenVarOpts.currentWorkers = count
-				i = 0
-			case <-time.After(1 * time.Second):
-				if GenVarOpts.UsePool {
-					trace("%v\n", pool.PoolStats())
-				}
-				if count > 0 {
-					info("there are still %d active workers\n", count)
-					trace("count = %d and i = %d", count, i)
-				} else {
-					verbose("count = 0 and i = %d\n", i)
-					i++
-					if i == 3 {
-						waitForWorkers <- true
-					}
-				}
+	baseGroup, err := newBaseGroup(&GenVarOpts.flagOpts, tables, wmap)
+	if err != nil {
+		return err
+	}
+
+	header, err := formatHeader(&GenVarOpts.flagOpts)
+	if err != nil {
+		return fmt.Errorf("bad output format options: %v", err)
+	}
+	out += header
+
+	// the groups appended to a results file continue its groups indices
+	var first int
+	var results *resultWriter
+	if GenVarOpts.OutResultFile != "" {
+		if GenVarOpts.AppendResult {
+			if first, err = resultGroups(&GenVarOpts.flagOpts); err != nil {
+				return fmt.Errorf("failed to read result file: %v", err)
 			}
 		}
-	}()
+		if results, err = newResultWriter(GenVarOpts.OutResultFile, GenVarOpts.AppendResult); err != nil {
+			return fmt.Errorf("failed to create result file: %v", err)
+		}
+		if results.empty() {
+			if err = results.write(header); err != nil {
+				results.Close()
+				return fmt.Errorf("failed to write result file: %v", err)
+			}
+		}
+	}
 
-	// msg collector
+	// stop early (and keep the results) on interrupt
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+	stop := make(chan struct{})
 	go func() {
-		for {
-			select {
-			case s := <-msgs:
-				if strings.HasPrefix(s, "depth: ") {
-					size, _ := strconv.Atoi(s[len("depth: "):])
-					if size > maxSize {
-						maxSize = size
-						verbose("max depth : %d\n", maxSize)
-					}
-				} else if strings.HasPrefix(s, "status:") {
-					info("%s", s)
-				} else {
-					GenVarOpts.countGroups++
-					out += s
-					info("%d\n%s", GenVarOpts.countGroups, s)
-					if GenVarOpts.countGroups == GenVarOpts.MaxGroups {
-						close(msgs)
-						close(collectingDone)
-						return
-					}
-				}
-			default:
-				time.Sleep(1 * time.Second)
-				verbose("%s passed\n", time.Now().Sub(t0))
-				if GenVarOpts.UsePool {
-					info("%v\n", pool.PoolStats())
-				}
-				if GenVarOpts.finishSignal {
-					info("finishSignal issued, exiting")
-					close(msgs)
-					return
-				}
-				debug("current workers %d, max workers %d\n",
-					GenVarOpts.currentWorkers, GenVarOpts.maxWorkers)
-			}
+		if sig, ok := <-interrupted; ok {
+			info("stopped by %v\n", sig)
+			close(stop)
 		}
 	}()
 
-	if GenVarOpts.UsePool {
-		pool.NewJobQueue(findGroups, findArg{baseGroup, wmap})
-	} else {
-		go findGroups(findArg{baseGroup, wmap}, nil, nil)
+	// a group which fails to format is left out, the first failure is
+	// returned once the search is over
+	var formatErr error
+	srch := newSearch(GenVarOpts.flagOpts)
+	srch.found = func(index int, g *cvc.GroupSet) {
+		index += first
+		s, err := formatGroup(&GenVarOpts.flagOpts, tables, index, g)
+		if err != nil {
+			if formatErr == nil {
+				formatErr = fmt.Errorf("failed to format group %d: %v", index, err)
+			}
+			return
+		}
+		out += s
+		if results != nil {
+			if err := results.write(s); err != nil {
+				fmt.Printf("failed to write result file: %v\n", err)
+			}
+		}
+		info("%d\n%s", index, s)
+	}
+	elapsed := srch.run(baseGroup, wmap, stop)
+	signal.Stop(interrupted)
+	close(interrupted)
+	fmt.Printf("exiting... after %s\n", elapsed)
+
+	footer := formatFooter(&GenVarOpts.flagOpts)
+	out += footer
+	if results != nil {
+		if err := results.write(footer); err != nil {
+			fmt.Printf("failed to write result file: %v\n", err)
+		}
+		if err := results.Close(); err != nil {
+			fmt.Printf("failed to save result file: %v\n", err)
+		}
 	}
 
-	dur := time.Duration(GenVarOpts.TimeToRun)
-	select {
-	case <-collectingDone:
-		info("required results collected after %s\n", time.Now().Sub(t0))
-	case <-time.After(dur * time.Second):
-		info("stopped after %s\n", time.Now().Sub(t0))
+	fmt.Println(out)
+	return formatErr
+}
+
+// loadWords load the alphabet, the transliteration table and the (filtered)
+// words list of opts
+func loadWords(opts *flagOpts) (*cvc.WordMap, *wordTables, error) {
+	tables, err := loadAlphabet(opts)
+	if err != nil {
+		return nil, nil, err
 	}
-	GenVarOpts.finishSignal = true
+	opts.verbose("consonants: %d\n%s\n", len(tables.consonants), getOrderedMapString(tables.consonants))
+	opts.verbose("vowels: %d\n%s\n", len(tables.vowels), getOrderedMapString(tables.vowels))
 
-	// pool cleanup
-	if GenVarOpts.UsePool {
-		ch := make(chan struct{})
-		if GenVarOpts.UseJobDispose {
-			close(disposeChan)
+	var filter *wordFilter
+	if opts.FilterFile != "" {
+		if filter, err = loadFilter(opts.FilterFile); err != nil {
+			return nil, nil, fmt.Errorf("failed to load filter: %v", err)
 		}
-		pool.StopDispatcher(func(){
-			select {
-				case <-disposeDone:
-			}
-			close(ch)
-		})
-		pool.Dispose()
-		<-ch
 	}
 
-	info("waiting for waitForWorkers, %d workers\n", GenVarOpts.currentWorkers)
-	<-waitForWorkers
-	fmt.Printf("exiting... after %s\n", time.Now().Sub(t0))
-
-	fmt.Println(out)
+	wmap, err := getWordsMap(opts, tables, opts.InWordsFile, filter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load words: %v", err)
+	}
+	if filter != nil {
+		fmt.Print(filter.summary())
+	}
+	opts.verbose("map size: %d\ncontent:\n%s\n", wmap.Size(), wmap)
+	return wmap, tables, nil
 }
 
 func getOrderedMapString(m map[string]int) string {
@@ -422,81 +482,296 @@ func getOrderedMapString(m map[string]int) string {
 	// return out
 }
 
-func getMap(mapfile string) map[string]int {
+// loadAlphabet load the consonants and vowels of opts, and the
+// transliteration table when one is given
+func loadAlphabet(opts *flagOpts) (*wordTables, error) {
+	var err error
+	tables := &wordTables{ipa: make(map[string]string), showIPA: opts.IPA}
+	if tables.consonants, err = getMap(opts.InConsonantFile, tables.ipa); err != nil {
+		return nil, fmt.Errorf("failed to load consonants: %v", err)
+	}
+	if tables.vowels, err = getMap(opts.InVowelFile, tables.ipa); err != nil {
+		return nil, fmt.Errorf("failed to load vowels: %v", err)
+	}
+	if opts.TranslitFile != "" {
+		if tables.translit, err = loadTranslit(opts.TranslitFile); err != nil {
+			return nil, fmt.Errorf("failed to load transliteration: %v", err)
+		}
+	}
+	return tables, nil
+}
+
+// getMap load the phonemes of mapfile, the phonemes ipa symbols (when
+// given) are added to ipa
+func getMap(mapfile string, ipa map[string]string) (map[string]int, error) {
+	wfs, err := getWordsFromFile(mapfile)
+	if err != nil {
+		return nil, err
+	}
 	var ret = make(map[string]int)
-	for _, wf := range getWordsFromFile(mapfile) {
+	for _, wf := range wfs {
 		ret[wf.word] = wf.number
+		if wf.ipa != "" {
+			ipa[wf.word] = wf.ipa
+		}
 	}
-	return ret
+	return ret, nil
 }
 
-func getWordsMap(fname string) *cvc.WordMap {
+func getWordsMap(opts *flagOpts, tables *wordTables, fname string, filter *wordFilter) (*cvc.WordMap, error) {
 	wmap := cvc.NewWordMap()
 
-	for _, wf := range getWordsFromFile(fname) {
-		var cvcw *cvc.Word
-		wfV := string(wf.word[1])
-		if _, ok := vowels[wfV]; ok {
-			cvcw = cvc.NewWord(
-				string(wf.word[0]),
-				string(wf.word[1]),
-				string(wf.word[2:]),
-				wf.number)
-		} else {
-			cvcw = cvc.NewWord(
-				string(wf.word[0:2]),
-				string(wf.word[2]),
-				string(wf.word[3:]),
-				wf.number)
+	entries, err := loadLexicon(fname)
+	if err != nil {
+		return nil, err
+	}
+	scaleFrequencies(entries, opts.FreqScale)
+	for _, e := range entries {
+		c1, v, c2, err := e.split(tables.vowels)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", fname, e.line, err)
+		}
+		if !filter.keep(c1, v, c2) {
+			continue
+		}
+		if _, ok := e.meta["hebrew"]; !ok && tables.translit != nil {
+			hebrew, err := tables.render(c1, v, c2, opts.Niqqud)
+			if err != nil {
+				return nil, fmt.Errorf("word '%s': %v", e.word, err)
+			}
+			if e.meta == nil {
+				e.meta = make(map[string]string)
+			}
+			e.meta["hebrew"] = hebrew
+		}
+		if _, ok := e.meta["ipa"]; !ok {
+			if ipa, ok := tables.wordIPA(c1, v, c2); ok {
+				if e.meta == nil {
+					e.meta = make(map[string]string)
+				}
+				e.meta["ipa"] = ipa
+			}
+		}
+		cvcw := cvc.NewWordMeta(c1, v, c2, e.freq, e.meta)
+
+		if e.word != cvcw.String() {
+			return nil, fmt.Errorf("%s:%d: loaded word '%s' and built word '%s' are not the same",
+				fname, e.line, e.word, cvcw)
+		}
+
+		if err := wmap.AddWordErr(cvcw); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", fname, e.line, err)
+		}
+	}
+	return wmap, nil
+}
+
+// newBaseGroup return the group the search start from, set according to
+// the opts settings and seeded when asked
+func newBaseGroup(opts *flagOpts, tables *wordTables, wmap *cvc.WordMap) (*cvc.GroupSet, error) {
+	baseGroup, err := cvc.NewGroupSetLimitFreqErr(
+		opts.MaxSets,
+		opts.MaxWords,
+		opts.FreqCutoff,
+		opts.FreqWordsPerLineAboveCutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.SeedFile != "" {
+		if err := seedGroup(tables, baseGroup, wmap, opts.SeedFile); err != nil {
+			return nil, fmt.Errorf("failed to seed group: %v", err)
+		}
+		opts.info("group seeded from '%v':%s\n", opts.SeedFile, baseGroup.StringWithFreq())
+	}
+
+	// no point searching (until the timeout) when the words can never fill
+	// the group
+	if reason := baseGroup.Infeasible(wmap); reason != "" {
+		return nil, fmt.Errorf("no group is possible: %s", reason)
+	}
+	return baseGroup, nil
+}
+
+// seedGroup add the fixed sets from seedfile to the group and remove their
+// words from the word map, so the search only fill the remaining sets.
+// each line hold one set, words are separated by spaces or commas, the
+// printed results format ("1:[JOD:2, JAK:1]") is accepted as well. a .json
+// seed file hold a saved group, or a json results line
+func seedGroup(tables *wordTables, group *cvc.GroupSet, wmap *cvc.WordMap, seedfile string) error {
+	byName := make(map[string]*cvc.Word)
+	for _, w := range wmap.Words() {
+		byName[w.String()] = w
+	}
+
+	readSets := readSeedLines
+	if strings.ToLower(filepath.Ext(seedfile)) == ".json" {
+		readSets = readSeedJSON
+	}
+	sets, err := readSets(seedfile)
+	if err != nil {
+		return err
+	}
+	for _, set := range sets {
+		var words cvc.WordList
+		for _, name := range set.names {
+			w, ok := byName[name]
+			if !ok {
+				return fmt.Errorf("%s: word '%s' is not in the words list", set.where, name)
+			}
+			words = append(words, w)
+		}
+
+		if err := group.AddSetErr(words); err != nil {
+			var names []string
+			for _, w := range words {
+				ipa, _ := w.Meta("ipa")
+				names = append(names, tables.showWord(w.String(), ipa))
+			}
+			return fmt.Errorf("%s: set [%s]: %v", set.where, strings.Join(names, ", "), err)
+		}
+		for _, w := range words {
+			wmap.DelWord(w)
 		}
+	}
+	return nil
+}
+
+// seedSet is the words of a seed set, where tells the set place in the file
+type seedSet struct {
+	where string
+	names []string
+}
 
-		if wf.word != cvcw.String() {
-			panic("loaded word: " + wf.word + " and built word: " +
-				cvcw.String() + " are NOT the same")
+func readSeedLines(seedfile string) ([]seedSet, error) {
+	lines, err := getLinesFromFile(seedfile)
+	if err != nil {
+		return nil, err
+	}
+	var sets []seedSet
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if s := strings.Index(line, "["); s != -1 {
+			line = strings.TrimRight(line[s+1:], "]")
 		}
 
-		wmap.AddWord(cvcw)
+		set := seedSet{where: fmt.Sprintf("line %d", i+1)}
+		for _, tok := range strings.FieldsFunc(line, func(r rune) bool {
+			return r == ' ' || r == ',' || r == '\t'
+		}) {
+			set.names = append(set.names, strings.SplitN(tok, ":", 2)[0])
+		}
+		sets = append(sets, set)
 	}
-	return wmap
+	return sets, nil
 }
 
-func checkErr(e error) {
-	if e != nil {
-		panic(e)
+// readSeedJSON read the sets of a json encoded group, the first results line
+// of the json output format is taken as well
+func readSeedJSON(seedfile string) ([]seedSet, error) {
+	f, err := os.Open(seedfile)
+	if err != nil {
+		return nil, err
 	}
+	defer f.Close()
+
+	var doc json.RawMessage
+	if err := json.NewDecoder(f).Decode(&doc); err != nil {
+		return nil, err
+	}
+	var result struct {
+		Group json.RawMessage `json:"group"`
+	}
+	if err := json.Unmarshal(doc, &result); err == nil && result.Group != nil {
+		doc = result.Group
+	}
+	var saved cvc.GroupSet
+	if err := json.Unmarshal(doc, &saved); err != nil {
+		return nil, err
+	}
+
+	var sets []seedSet
+	for i, set := range saved.Sets() {
+		seed := seedSet{where: fmt.Sprintf("set %d", i+1)}
+		for _, w := range set.Words() {
+			seed.names = append(seed.names, w.String())
+		}
+		sets = append(sets, seed)
+	}
+	return sets, nil
 }
 
-// WF - word number bundle
+func checkOptsErr(err error) {
+	if err != nil {
+		if e, ok := err.(*flags.Error); ok {
+			switch e.Type {
+			case flags.ErrHelp:
+				os.Exit(0)
+			default:
+				fmt.Printf("error parsing opts: %v\n", e.Type)
+				os.Exit(1)
+			}
+		}
+	}
+}
+
+// WF - word number bundle, phonemes may carry their ipa symbol
 type WF struct {
 	word   string
 	number int
+	ipa    string
 }
 
-func getLinesFromFile(fname string) []string {
+func getLinesFromFile(fname string) ([]string, error) {
 	data, err := ioutil.ReadFile(fname)
-	checkErr(err)
+	if err != nil {
+		return nil, err
+	}
 
 	data = bytes.TrimRight(data, "\n")
 	lines := strings.Split(string(data), "\n")
-	return lines
+	return lines, nil
 }
 
-func getWordsFromFile(fname string) []WF {
+func getWordsFromFile(fname string) ([]WF, error) {
 	resList := []WF{}
 
-	for _, line := range getLinesFromFile(fname) {
-		tmp := strings.Split(line, " ")
-		w := strings.TrimRight(tmp[0], ":")
-		f, _ := strconv.Atoi(tmp[1])
-		resList = append(resList, WF{w, f})
+	lines, err := getLinesFromFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range lines {
+		var ipa string
+		if fields := strings.Fields(line); len(fields) == 3 {
+			line, ipa = strings.Join(fields[:2], " "), fields[2]
+		}
+		w, f, err := parseWordLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", fname, i+1, err)
+		}
+		resList = append(resList, WF{w, f, ipa})
 	}
-	return resList
+	return resList, nil
 }
 
-func debug(f string, v ...interface{}) { if GenVarOpts.DebugEnabled { fmt.Printf("debug: " + f, v...) } }
+func (fo *flagOpts) debug(f string, v ...interface{}) { if fo.DebugEnabled { fmt.Printf("debug: " + f, v...) } }
+
+func (fo *flagOpts) info(f string, v ...interface{}) { if len(fo.Verbose) >= 1 && fo.Verbose[0] { fmt.Printf("info: "+f, v...) } }
+
+func (fo *flagOpts) verbose(f string, v ...interface{}) { if len(fo.Verbose) >= 2 && fo.Verbose[1] { fmt.Printf("verbose" + f, v...) } }
+
+func (fo *flagOpts) trace(f string, v ...interface{}) { if len(fo.Verbose) >= 3 && fo.Verbose[2] { fmt.Printf("trace" + f, v...) } }
+
+// the logging of the command line options, a serve job search log with its
+// own options
+
+func debug(f string, v ...interface{}) { GenVarOpts.debug(f, v...) }
 
-func info(f string, v ...interface{}) { if len(GenVarOpts.Verbose) >= 1 && GenVarOpts.Verbose[0] { fmt.Printf("info: "+f, v...) } }
+func info(f string, v ...interface{}) { GenVarOpts.info(f, v...) }
 
-func verbose(f string, v ...interface{}) { if len(GenVarOpts.Verbose) >= 2 && GenVarOpts.Verbose[1] { fmt.Printf("verbose" + f, v...) } }
+func verbose(f string, v ...interface{}) { GenVarOpts.verbose(f, v...) }
 
-func trace(f string, v ...interface{}) { if len(GenVarOpts.Verbose) >= 3 && GenVarOpts.Verbose[2] { fmt.Printf("trace" + f, v...) } }
+func trace(f string, v ...interface{}) { GenVarOpts.trace(f, v...) }
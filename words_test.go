@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gilwo/wordscvc/cvc"
+)
+
+// writeTestFile write data to a file named name in the test temporary
+// directory and return its path
+func writeTestFile(t *testing.T, name, data string) string {
+	t.Helper()
+	fname := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(fname, []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", fname, err)
+	}
+	return fname
+}
+
+// seedSetsString render the seed sets as "where:[names]" for the compare
+func seedSetsString(sets []seedSet) string {
+	var out []string
+	for _, set := range sets {
+		out = append(out, fmt.Sprintf("%s:%v", set.where, set.names))
+	}
+	return strings.Join(out, " ")
+}
+
+func TestSeedLines(t *testing.T) {
+	sets, err := readSeedLines(writeTestFile(t, "seed.txt",
+		"# fixed sets\nBOR JOD\n\n  LAM, TZAF\t\n\t3:[JAD:2, JAK:1]\n"))
+	if err != nil {
+		t.Fatalf("failed to read the seed lines: %v", err)
+	}
+	expected := "line 2:[BOR JOD] line 4:[LAM TZAF] line 5:[JAD JAK]"
+	if actual := seedSetsString(sets); actual != expected {
+		t.Errorf("seed sets: expected '%s', actual '%s'", expected, actual)
+	}
+
+	sets, err = readSeedLines(writeTestFile(t, "seed.txt", ""))
+	if err != nil || len(sets) != 0 {
+		t.Errorf("empty seed file read as %v, %v", sets, err)
+	}
+}
+
+func TestSeedGroup(t *testing.T) {
+	words := []*cvc.Word{
+		cvc.NewWord("B", "O", "R", 75), cvc.NewWord("J", "A", "D", 2),
+		cvc.NewWord("L", "A", "M", 9), cvc.NewWord("T", "U", "SH", 4)}
+	wmap := cvc.NewWordMap()
+	for _, w := range words {
+		wmap.AddWord(w)
+	}
+
+	group := cvc.NewGroupSetLimit(2, 2)
+	err := seedGroup(&wordTables{}, group, wmap, writeTestFile(t, "seed.txt", "BOR, JAD\nLAM\n"))
+	if err != nil {
+		t.Fatalf("failed to seed group: %v", err)
+	}
+	if group.String() != "\n\t[BOR, JAD]\n\t[LAM]\n" {
+		t.Errorf("seeded group is '%s'", group)
+	}
+	if wmap.Size() != 1 || !wmap.Contains(words[3]) {
+		t.Errorf("seeded words are still in the words map %s", wmap)
+	}
+
+	err = seedGroup(&wordTables{}, cvc.NewGroupSetLimit(2, 2), wmap, writeTestFile(t, "seed.txt", "TUSH GIL\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 1: word 'GIL' is not in the words list") {
+		t.Errorf("seed with an unknown word: %v", err)
+	}
+}
+
+func TestSeedJSON(t *testing.T) {
+	group := cvc.NewGroupSetLimit(3, 2)
+	group.AddWord(cvc.NewWord("B", "O", "R", 75))
+	group.AddWord(cvc.NewWord("J", "A", "D", 2))
+	group.AddWord(cvc.NewWord("L", "A", "M", 9))
+	data, err := json.Marshal(group)
+	if err != nil {
+		t.Fatalf("failed to marshal group %s: %v", group, err)
+	}
+
+	expected := "set 1:[BOR JAD] set 2:[LAM]"
+	sets, err := readSeedJSON(writeTestFile(t, "seed.json", string(data)))
+	if err != nil {
+		t.Fatalf("failed to read the saved group: %v", err)
+	}
+	if actual := seedSetsString(sets); actual != expected {
+		t.Errorf("saved group sets: expected '%s', actual '%s'", expected, actual)
+	}
+
+	// the first line of the json output format
+	line := fmt.Sprintf(`{"index":1,"params":{},"group":%s}`+"\n"+`{"index":2}`, data)
+	if sets, err = readSeedJSON(writeTestFile(t, "seed.json", line)); err != nil {
+		t.Fatalf("failed to read the results line: %v", err)
+	}
+	if actual := seedSetsString(sets); actual != expected {
+		t.Errorf("results line sets: expected '%s', actual '%s'", expected, actual)
+	}
+
+	_, err = readSeedJSON(writeTestFile(t, "seed.json",
+		`{"sets":[{"words":[{"c1":"B","v":"O","c2":"R"},{"c1":"R","v":"A","c2":"D"}]}],"max_sets":1,"max_words":2}`))
+	if err == nil || !strings.Contains(err.Error(), "consonant") {
+		t.Errorf("saved group breaking the set rules read with %v", err)
+	}
+}
+
+// testWordsList is a words list over the test alphabet, NOP and MIL are
+// above the frequency cutoff 25
+const testWordsList = "BAD: 5\nGEK: 9\nLIM: 1\nNOP: 30\nRUT: 2\nDAB: 7\nKEG: 3\nMIL: 40\nPON: 6\nTUR: 8\n"
+
+// setTestWords write a small alphabet and the words list to the test
+// temporary directory and set the options to load them, with groups of 2
+// sets of 2 words and 1 word above the frequency cutoff 25
+func setTestWords(t *testing.T, words string) string {
+	t.Helper()
+	fname := writeTestFile(t, "words_list.txt", words)
+	dir := filepath.Dir(fname)
+	for name, data := range map[string]string{
+		"consonants.txt": "B: 1 b\nD: 1 d\nG: 1 ɡ\nK: 1 k\nL: 1 l\nM: 1 m\nN: 1 n\nP: 1 p\nR: 1 ʁ\nT: 1 t\n",
+		"vowels.txt":     "A: 1 a\nE: 1 e\nI: 1 i\nO: 1 o\nU: 1 u\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	resetOpts()
+	t.Cleanup(resetOpts)
+	GenVarOpts.MaxSets, GenVarOpts.MaxWords = 2, 2
+	GenVarOpts.FreqCutoff, GenVarOpts.FreqWordsPerLineAboveCutoff = 25, 1
+	GenVarOpts.FreqScale = "raw"
+	GenVarOpts.InConsonantFile = filepath.Join(dir, "consonants.txt")
+	GenVarOpts.InVowelFile = filepath.Join(dir, "vowels.txt")
+	GenVarOpts.InWordsFile = fname
+	return dir
+}
+
+// captureOutput return what f print to the standard output, and its error
+func captureOutput(t *testing.T, f func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	saved := os.Stdout
+	os.Stdout = w
+	out := make(chan string)
+	go func() {
+		data, _ := ioutil.ReadAll(r)
+		out <- string(data)
+	}()
+
+	err = f()
+	os.Stdout = saved
+	w.Close()
+	return <-out, err
+}